@@ -1,37 +1,162 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
 	"github.com/milyrock/PR-Reviewer/internal/app"
+	"github.com/milyrock/PR-Reviewer/internal/auth"
 	"github.com/milyrock/PR-Reviewer/internal/config"
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
 	v1 "github.com/milyrock/PR-Reviewer/internal/handlers/v1"
+	v2 "github.com/milyrock/PR-Reviewer/internal/handlers/v2"
+	"github.com/milyrock/PR-Reviewer/internal/metrics"
 	"github.com/milyrock/PR-Reviewer/internal/repository"
+	"github.com/milyrock/PR-Reviewer/internal/repository/sqlite"
+	"github.com/milyrock/PR-Reviewer/internal/service"
 )
 
 func main() {
-	cfg, err := config.ReadConfig("./config/config.yaml")
+	configPath := flag.String("config", "./config/config.yaml", "path to the configuration file")
+	flag.Parse()
+
+	cfg, err := config.ReadConfig(*configPath)
 	if err != nil {
-		log.Fatalf("Failed to read config: %v", err)
+		fmt.Fprintf(os.Stderr, "Failed to read config %s:\n%v\n", *configPath, err)
+		os.Exit(1)
 	}
 
-	db, err := app.InitDB(cfg.Database)
+	app.SetupLogging(cfg.Logging)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	err = run(ctx, cfg)
+	stop()
+
 	if err != nil {
-		log.Fatalf("Failed to init db: %v", err)
+		slog.Error("service failed", "error", err)
+		os.Exit(1)
 	}
-	defer db.Close()
+}
 
-	repo := repository.NewRepository(db)
+// run serves until ctx is cancelled or the listener fails. The listener starts
+// first and reports not ready until the database is reachable and migrated.
+// On the way out in-flight requests are drained, then the background workers
+// stopped, then the database closed.
+func run(ctx context.Context, cfg *config.Config) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	r := mux.NewRouter()
+	startup := app.NewStartup()
+	srv := app.NewServer(cfg.Server, startup)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("server starting", "addr", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+		cancel()
+	}()
+
+	workers := app.NewWorkers()
+
+	var db *sqlx.DB
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Error("failed to drain in-flight requests", "error", err)
+		}
+		if err := workers.Stop(shutdownCtx); err != nil {
+			slog.Error("failed to stop background workers", "error", err)
+		}
+		if db != nil {
+			db.Close()
+		}
+	}()
+
+	db, repo, err := openStore(ctx, cfg)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Interrupted while waiting for the database.
+			return stopped(serveErr)
+		}
+		return fmt.Errorf("failed to init db: %w", err)
+	}
+
+	if !cfg.Features.DisableMetrics {
+		if err := metrics.RegisterDB(db.DB, cfg.Storage.Driver, repo); err != nil {
+			return fmt.Errorf("failed to register metrics: %w", err)
+		}
+	}
+
+	var jwtVerifier *auth.Verifier
+	if cfg.Auth.JWT.Enabled {
+		jwtVerifier, err = auth.NewVerifier(cfg.Auth.JWT)
+		if err != nil {
+			return fmt.Errorf("failed to init jwt verifier: %w", err)
+		}
+	}
 
-	api := v1.NewAPI(repo)
+	r := mux.NewRouter()
+	api := v1.NewAPI(repo, cfg.Server, cfg.Auth, cfg.Features, jwtVerifier, workers)
 	api.RegisterHandlers(r)
+	v2.NewAPI(repo, httpx.Authorizer{Enabled: cfg.Auth.Enabled}).RegisterHandlers(r)
+
+	idempotency := service.NewIdempotencyService(repo, cfg.Features.IdempotencyTTL, service.DefaultIdempotencyLease)
+	workers.Every("idempotency_purge", cfg.Workers.IdempotencyPurgeInterval, func(ctx context.Context) error {
+		purged, err := idempotency.PurgeExpired(ctx)
+		if err == nil && purged > 0 {
+			slog.InfoContext(ctx, "purged expired idempotency keys", "count", purged)
+		}
+		return err
+	})
+
+	startup.Ready(r)
+	slog.Info("service is ready")
+
+	<-ctx.Done()
+
+	return stopped(serveErr)
+}
+
+// stopped reports why run is returning: a listener failure, or a signal.
+func stopped(serveErr <-chan error) error {
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed: %w", err)
+		}
+	default:
+		slog.Info("shutting down")
+	}
+
+	return nil
+}
 
-	log.Println("Server starting on port 8080")
-	if err := http.ListenAndServe(":8080", r); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+func openStore(ctx context.Context, cfg *config.Config) (*sqlx.DB, service.Store, error) {
+	switch cfg.Storage.Driver {
+	case config.DriverPostgres:
+		db, err := app.InitDB(ctx, cfg.Database)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, repository.NewRepository(db), nil
+	case config.DriverSQLite:
+		db, err := app.InitSQLite(cfg.Storage.SQLite)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, sqlite.NewStore(db), nil
+	default:
+		return nil, nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
 	}
 }
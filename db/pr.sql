@@ -10,7 +10,8 @@ CREATE TABLE users (
     user_id VARCHAR(50) PRIMARY KEY,
     username VARCHAR(100) NOT NULL,
     team_name VARCHAR(100) NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
-    is_active BOOLEAN NOT NULL DEFAULT TRUE
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
 DROP TABLE IF EXISTS pull_requests;
@@ -20,6 +21,7 @@ CREATE TABLE pull_requests (
     author_id VARCHAR(50) NOT NULL REFERENCES users(user_id),
     status VARCHAR(10) NOT NULL CHECK (status IN ('OPEN', 'MERGED')),
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
     merged_at TIMESTAMP WITH TIME ZONE
 );
 
@@ -27,7 +29,47 @@ DROP TABLE IF EXISTS pr_reviewers;
 CREATE TABLE pr_reviewers (
     pull_request_id VARCHAR(50) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
     user_id VARCHAR(50) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    assigned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
     PRIMARY KEY (pull_request_id, user_id)
 );
 
+DROP TABLE IF EXISTS review_decisions;
+CREATE TABLE review_decisions (
+    pull_request_id VARCHAR(50) NOT NULL,
+    user_id VARCHAR(50) NOT NULL,
+    decision VARCHAR(20) NOT NULL CHECK (decision IN ('APPROVED', 'CHANGES_REQUESTED')),
+    decided_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    first_decided_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (pull_request_id, user_id),
+    FOREIGN KEY (pull_request_id, user_id) REFERENCES pr_reviewers(pull_request_id, user_id) ON DELETE CASCADE
+);
+
+DROP TABLE IF EXISTS api_keys;
+CREATE TABLE api_keys (
+    key_id VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'integration', 'read_only')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS idempotency_records;
+CREATE TABLE idempotency_records (
+    idempotency_key VARCHAR(64) PRIMARY KEY,
+    request_hash CHAR(64) NOT NULL,
+    status_code INTEGER,
+    response_headers JSONB,
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+DROP TABLE IF EXISTS schema_version;
+CREATE TABLE schema_version (
+    version INTEGER NOT NULL
+);
+INSERT INTO schema_version (version) VALUES (3);
+
 COMMIT;
\ No newline at end of file
@@ -0,0 +1,70 @@
+CREATE TABLE IF NOT EXISTS teams (
+    team_name TEXT PRIMARY KEY
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    user_id TEXT PRIMARY KEY,
+    username TEXT NOT NULL,
+    team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS pull_requests (
+    pull_request_id TEXT PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id TEXT NOT NULL REFERENCES users(user_id),
+    status TEXT NOT NULL CHECK (status IN ('OPEN', 'MERGED')),
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL,
+    merged_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS pr_reviewers (
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    assigned_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (pull_request_id, user_id)
+);
+
+-- Added in version 3. A row exists once the reviewer has made a decision;
+-- it is removed with the assignment.
+CREATE TABLE IF NOT EXISTS review_decisions (
+    pull_request_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    decision TEXT NOT NULL CHECK (decision IN ('APPROVED', 'CHANGES_REQUESTED')),
+    decided_at TIMESTAMP NOT NULL,
+    first_decided_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (pull_request_id, user_id),
+    FOREIGN KEY (pull_request_id, user_id) REFERENCES pr_reviewers(pull_request_id, user_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    key_id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    role TEXT NOT NULL CHECK (role IN ('admin', 'integration', 'read_only')),
+    created_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+
+-- Version 2 replaced idempotency_keys, which kept only the Content-Type of a
+-- stored response. The records expire within a day, so the old table is
+-- dropped rather than migrated.
+DROP TABLE IF EXISTS idempotency_keys;
+
+CREATE TABLE IF NOT EXISTS idempotency_records (
+    idempotency_key TEXT PRIMARY KEY,
+    request_hash TEXT NOT NULL,
+    status_code INTEGER,
+    response_headers TEXT,
+    response_body BLOB,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS schema_version (
+    version INTEGER NOT NULL
+);
+INSERT INTO schema_version (version)
+SELECT 3 WHERE NOT EXISTS (SELECT 1 FROM schema_version WHERE version >= 3);
@@ -1,19 +1,28 @@
 package e2e
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/milyrock/PR-Reviewer/internal/config"
 	v1 "github.com/milyrock/PR-Reviewer/internal/handlers/v1"
+	"github.com/milyrock/PR-Reviewer/internal/metrics"
 	"github.com/milyrock/PR-Reviewer/internal/models"
 	"github.com/milyrock/PR-Reviewer/internal/repository"
 	"github.com/milyrock/PR-Reviewer/internal/test"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,22 +34,34 @@ func setupTestServer(t *testing.T) (*httptest.Server, func()) {
 
 	repo := repository.NewRepository(db)
 
+	healthHandler := v1.NewHealthHandler(repo, nil)
 	teamHandler := v1.NewTeamHandler(repo)
 	userHandler := v1.NewUserHandler(repo)
 	prHandler := v1.NewPRHandler(repo)
 	statisticsHandler := v1.NewStatisticsHandler(repo)
 
 	r := mux.NewRouter()
+	r.Use(metrics.Middleware)
 
-	r.HandleFunc("/health", v1.Health).Methods("GET")
+	r.HandleFunc("/health", healthHandler.Live).Methods("GET")
+	r.HandleFunc("/health/live", healthHandler.Live).Methods("GET")
+	r.HandleFunc("/health/ready", healthHandler.Ready).Methods("GET")
 	r.HandleFunc("/team/add", teamHandler.AddTeam).Methods("POST")
 	r.HandleFunc("/team/get", teamHandler.GetTeam).Methods("GET")
 	r.HandleFunc("/users/setIsActive", userHandler.SetIsActive).Methods("POST")
 	r.HandleFunc("/users/getReview", userHandler.GetReview).Methods("GET")
+	r.HandleFunc("/users/dashboard", userHandler.GetDashboard).Methods("GET")
 	r.HandleFunc("/pullRequest/create", prHandler.CreatePR).Methods("POST")
 	r.HandleFunc("/pullRequest/merge", prHandler.MergePR).Methods("POST")
 	r.HandleFunc("/pullRequest/reassign", prHandler.ReassignPR).Methods("POST")
+	r.HandleFunc("/pullRequest/list", prHandler.ListPRs).Methods("GET")
+	r.HandleFunc("/pullRequest/get", prHandler.GetPR).Methods("GET")
 	r.HandleFunc("/statistics", statisticsHandler.GetStatistics).Methods("GET")
+	r.HandleFunc("/statistics/latency", statisticsHandler.GetLatency).Methods("GET")
+	r.HandleFunc("/statistics/fairness", statisticsHandler.GetFairness).Methods("GET")
+	r.HandleFunc("/statistics/graph", statisticsHandler.GetGraph).Methods("GET")
+	r.HandleFunc("/export/prs", prHandler.ExportPRs).Methods("GET")
+	r.Handle("/metrics", promhttp.Handler()).Methods("GET")
 
 	server := httptest.NewServer(r)
 
@@ -58,6 +79,28 @@ func TestHealthEndpoint(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
 
+func TestReadinessEndpoint(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	resp, err := http.Get(server.URL + "/health/ready")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var report models.HealthReport
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, models.HealthStatusOK, report.Status)
+
+	names := make([]string, 0, len(report.Checks))
+	for _, check := range report.Checks {
+		assert.Equal(t, models.HealthStatusOK, check.Status, check.Name)
+		names = append(names, check.Name)
+	}
+	assert.Equal(t, []string{"database", "schema"}, names)
+}
+
 func TestCreateTeamAndPR(t *testing.T) {
 	server, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -229,6 +272,136 @@ func TestReassignReviewer(t *testing.T) {
 	resp.Body.Close()
 }
 
+func postConcurrently(t *testing.T, url string, bodies [][]byte) []*http.Response {
+	responses := make([]*http.Response, len(bodies))
+	errs := make([]error, len(bodies))
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i, body := range bodies {
+		wg.Add(1)
+		go func(i int, body []byte) {
+			defer wg.Done()
+			<-start
+			responses[i], errs[i] = http.Post(url, "application/json", bytes.NewReader(body))
+		}(i, body)
+	}
+	close(start)
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	return responses
+}
+
+func TestConcurrentCreatePR(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	teamBody, _ := json.Marshal(models.CreateTeamRequest{
+		TeamName: "race",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+			{UserID: "u3", Username: "Charlie", IsActive: true},
+		},
+	})
+	resp, err := http.Post(server.URL+"/team/add", "application/json", bytes.NewBuffer(teamBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	prBody, _ := json.Marshal(models.CreatePRRequest{PullRequestID: "pr-race", PullRequestName: "Race", AuthorID: "u1"})
+	bodies := make([][]byte, 20)
+	for i := range bodies {
+		bodies[i] = prBody
+	}
+
+	created := 0
+	for _, resp := range postConcurrently(t, server.URL+"/pullRequest/create", bodies) {
+		switch resp.StatusCode {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+			var errResp models.ErrorResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+			assert.Equal(t, "PR_EXISTS", errResp.Error.Code)
+		default:
+			t.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+	}
+	assert.Equal(t, 1, created)
+}
+
+func TestConcurrentReassign(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	teamBody, _ := json.Marshal(models.CreateTeamRequest{
+		TeamName: "race",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+			{UserID: "u3", Username: "Charlie", IsActive: true},
+			{UserID: "u4", Username: "David", IsActive: true},
+		},
+	})
+	resp, err := http.Post(server.URL+"/team/add", "application/json", bytes.NewBuffer(teamBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	prBody, _ := json.Marshal(models.CreatePRRequest{PullRequestID: "pr-race", PullRequestName: "Race", AuthorID: "u1"})
+	resp, err = http.Post(server.URL+"/pullRequest/create", "application/json", bytes.NewBuffer(prBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var createResp struct {
+		PR models.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&createResp))
+	resp.Body.Close()
+	require.Len(t, createResp.PR.AssignedReviewers, 2)
+
+	// Both reviewers are replaced at once while only one spare teammate is
+	// free; every request must see the reviewers left by the previous one.
+	var bodies [][]byte
+	for i := 0; i < 10; i++ {
+		for _, reviewer := range createResp.PR.AssignedReviewers {
+			body, _ := json.Marshal(models.ReassignPRRequest{PullRequestID: "pr-race", OldUserID: reviewer})
+			bodies = append(bodies, body)
+		}
+	}
+
+	reassigned := 0
+	for _, resp := range postConcurrently(t, server.URL+"/pullRequest/reassign", bodies) {
+		switch resp.StatusCode {
+		case http.StatusOK:
+			reassigned++
+		case http.StatusConflict:
+			var errResp models.ErrorResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+			assert.Contains(t, []string{"NOT_ASSIGNED", "NO_CANDIDATE"}, errResp.Error.Code)
+		default:
+			t.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+	}
+
+	resp, err = http.Get(server.URL + "/pullRequest/get?pull_request_id=pr-race")
+	require.NoError(t, err)
+	var getResp struct {
+		PR models.PullRequest `json:"pr"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&getResp))
+	resp.Body.Close()
+
+	assert.Len(t, getResp.PR.AssignedReviewers, 2)
+	assert.NotContains(t, getResp.PR.AssignedReviewers, "u1")
+	assert.GreaterOrEqual(t, reassigned, 1)
+}
+
 func TestInactiveUserNotAssigned(t *testing.T) {
 	server, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -424,3 +597,859 @@ func TestMergeIdempotency(t *testing.T) {
 	assert.Equal(t, "MERGED", mergeResp.PR.Status)
 	resp.Body.Close()
 }
+
+func TestListPRs(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	teamReq := models.CreateTeamRequest{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+		},
+	}
+
+	teamBody, _ := json.Marshal(teamReq)
+	resp, err := http.Post(server.URL+"/team/add", "application/json", bytes.NewBuffer(teamBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	for i := 1; i <= 3; i++ {
+		prReq := models.CreatePRRequest{
+			PullRequestID:   fmt.Sprintf("pr-%d", i),
+			PullRequestName: fmt.Sprintf("Feature %d", i),
+			AuthorID:        "u1",
+		}
+
+		prBody, _ := json.Marshal(prReq)
+		resp, err = http.Post(server.URL+"/pullRequest/create", "application/json", bytes.NewBuffer(prBody))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	mergeBody, _ := json.Marshal(models.MergePRRequest{PullRequestID: "pr-2"})
+	resp, err = http.Post(server.URL+"/pullRequest/merge", "application/json", bytes.NewBuffer(mergeBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + "/pullRequest/list?status=OPEN&reviewer_id=u2&team_name=backend")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var listResp models.PRListResponse
+	err = json.NewDecoder(resp.Body).Decode(&listResp)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Len(t, listResp.PullRequests, 2)
+	for _, pr := range listResp.PullRequests {
+		assert.Equal(t, "OPEN", pr.Status)
+		assert.Contains(t, pr.AssignedReviewers, "u2")
+	}
+
+	var seen []string
+	cursor := ""
+	for {
+		resp, err = http.Get(server.URL + "/pullRequest/list?order=asc&limit=2&cursor=" + cursor)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var page models.PRListResponse
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		for _, pr := range page.PullRequests {
+			seen = append(seen, pr.PullRequestID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	assert.Equal(t, []string{"pr-1", "pr-2", "pr-3"}, seen)
+
+	resp, err = http.Get(server.URL + "/pullRequest/list?cursor=garbage")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func TestGetPR(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	teamReq := models.CreateTeamRequest{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+			{UserID: "u3", Username: "Charlie", IsActive: true},
+		},
+	}
+
+	teamBody, _ := json.Marshal(teamReq)
+	resp, err := http.Post(server.URL+"/team/add", "application/json", bytes.NewBuffer(teamBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	prBody, _ := json.Marshal(models.CreatePRRequest{
+		PullRequestID:   "pr-7",
+		PullRequestName: "Fetch me",
+		AuthorID:        "u1",
+	})
+	resp, err = http.Post(server.URL+"/pullRequest/create", "application/json", bytes.NewBuffer(prBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + "/pullRequest/get?pull_request_id=pr-7")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	var getResp struct {
+		PR models.PullRequest `json:"pr"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&getResp)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "pr-7", getResp.PR.PullRequestID)
+	require.Len(t, getResp.PR.Reviewers, 2)
+	for _, reviewer := range getResp.PR.Reviewers {
+		assert.Equal(t, "backend", reviewer.TeamName)
+		assert.NotEmpty(t, reviewer.Username)
+		assert.False(t, reviewer.AssignedAt.IsZero())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/pullRequest/get?pull_request_id=pr-7", nil)
+	require.NoError(t, err)
+	req.Header.Set("If-None-Match", etag)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	resp.Body.Close()
+
+	mergeBody, _ := json.Marshal(models.MergePRRequest{PullRequestID: "pr-7"})
+	resp, err = http.Post(server.URL+"/pullRequest/merge", "application/json", bytes.NewBuffer(mergeBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"))
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + "/pullRequest/get?pull_request_id=missing")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func TestUserDashboard(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	teamReq := models.CreateTeamRequest{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+		},
+	}
+
+	teamBody, _ := json.Marshal(teamReq)
+	resp, err := http.Post(server.URL+"/team/add", "application/json", bytes.NewBuffer(teamBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	for _, prReq := range []models.CreatePRRequest{
+		{PullRequestID: "pr-1", PullRequestName: "Authored by Alice", AuthorID: "u1"},
+		{PullRequestID: "pr-2", PullRequestName: "Authored by Bob", AuthorID: "u2"},
+		{PullRequestID: "pr-3", PullRequestName: "Merged by Alice", AuthorID: "u1"},
+	} {
+		prBody, _ := json.Marshal(prReq)
+		resp, err = http.Post(server.URL+"/pullRequest/create", "application/json", bytes.NewBuffer(prBody))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	mergeBody, _ := json.Marshal(models.MergePRRequest{PullRequestID: "pr-3"})
+	resp, err = http.Post(server.URL+"/pullRequest/merge", "application/json", bytes.NewBuffer(mergeBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + "/users/dashboard?user_id=u1")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var dashboard models.UserDashboard
+	err = json.NewDecoder(resp.Body).Decode(&dashboard)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Len(t, dashboard.ToReview, 1)
+	assert.Equal(t, "pr-2", dashboard.ToReview[0].PullRequestID)
+
+	require.Len(t, dashboard.Authored, 1)
+	assert.Equal(t, "pr-1", dashboard.Authored[0].PullRequestID)
+	require.Len(t, dashboard.Authored[0].Reviewers, 1)
+	assert.Equal(t, "u2", dashboard.Authored[0].Reviewers[0].UserID)
+	assert.True(t, dashboard.Authored[0].Reviewers[0].IsActive)
+
+	require.Len(t, dashboard.RecentlyMerged, 1)
+	assert.Equal(t, "pr-3", dashboard.RecentlyMerged[0].PullRequestID)
+}
+
+func TestStatisticsFilters(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	for _, teamReq := range []models.CreateTeamRequest{
+		{
+			TeamName: "backend",
+			Members: []models.TeamMember{
+				{UserID: "u1", Username: "Alice", IsActive: true},
+				{UserID: "u2", Username: "Bob", IsActive: true},
+			},
+		},
+		{
+			TeamName: "frontend",
+			Members: []models.TeamMember{
+				{UserID: "u3", Username: "Charlie", IsActive: true},
+				{UserID: "u4", Username: "David", IsActive: true},
+			},
+		},
+	} {
+		teamBody, _ := json.Marshal(teamReq)
+		resp, err := http.Post(server.URL+"/team/add", "application/json", bytes.NewBuffer(teamBody))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	for _, prReq := range []models.CreatePRRequest{
+		{PullRequestID: "pr-1", PullRequestName: "Backend change", AuthorID: "u1"},
+		{PullRequestID: "pr-2", PullRequestName: "Frontend change", AuthorID: "u3"},
+	} {
+		prBody, _ := json.Marshal(prReq)
+		resp, err := http.Post(server.URL+"/pullRequest/create", "application/json", bytes.NewBuffer(prBody))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(server.URL + "/statistics?team_name=backend&status=OPEN&bucket=day")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var statsResp models.StatisticsResponse
+	err = json.NewDecoder(resp.Body).Decode(&statsResp)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Len(t, statsResp.PRStats, 1)
+	assert.Equal(t, "pr-1", statsResp.PRStats[0].PullRequestID)
+	require.Len(t, statsResp.UserStats, 2)
+	assert.Equal(t, "u2", statsResp.UserStats[0].UserID)
+	assert.Equal(t, 1, statsResp.UserStats[0].ReviewCount)
+	require.Len(t, statsResp.Timeline, 1)
+	assert.Equal(t, 1, statsResp.Timeline[0].PRsCreated)
+	assert.Equal(t, 1, statsResp.Timeline[0].Assignments)
+
+	resp, err = http.Get(server.URL + "/statistics?from=2100-01-01T00:00:00Z")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	statsResp = models.StatisticsResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&statsResp)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Empty(t, statsResp.PRStats)
+	for _, userStat := range statsResp.UserStats {
+		assert.Zero(t, userStat.ReviewCount)
+	}
+
+	resp, err = http.Get(server.URL + "/statistics?bucket=month")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func TestLatencyStatistics(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	teamReq := models.CreateTeamRequest{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+		},
+	}
+
+	teamBody, _ := json.Marshal(teamReq)
+	resp, err := http.Post(server.URL+"/team/add", "application/json", bytes.NewBuffer(teamBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	for i := 1; i <= 2; i++ {
+		prBody, _ := json.Marshal(models.CreatePRRequest{
+			PullRequestID:   fmt.Sprintf("pr-%d", i),
+			PullRequestName: fmt.Sprintf("Feature %d", i),
+			AuthorID:        "u1",
+		})
+		resp, err = http.Post(server.URL+"/pullRequest/create", "application/json", bytes.NewBuffer(prBody))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp.Body.Close()
+
+		mergeBody, _ := json.Marshal(models.MergePRRequest{PullRequestID: fmt.Sprintf("pr-%d", i)})
+		resp, err = http.Post(server.URL+"/pullRequest/merge", "application/json", bytes.NewBuffer(mergeBody))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	resp, err = http.Get(server.URL + "/statistics/latency?team_name=backend")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var latency models.LatencyResponse
+	err = json.NewDecoder(resp.Body).Decode(&latency)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Len(t, latency.TimeToMergeByTeam, 1)
+	assert.Equal(t, "backend", latency.TimeToMergeByTeam[0].Key)
+	assert.Equal(t, 2, latency.TimeToMergeByTeam[0].Count)
+	assert.GreaterOrEqual(t, latency.TimeToMergeByTeam[0].P99Seconds, latency.TimeToMergeByTeam[0].P50Seconds)
+	require.Len(t, latency.AssignmentToMergeByReviewer, 1)
+	assert.Equal(t, "u2", latency.AssignmentToMergeByReviewer[0].Key)
+	assert.Len(t, latency.TimeToMergeByWeek, 1)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/statistics/latency", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/csv")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv", resp.Header.Get("Content-Type"))
+	resp.Body.Close()
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	teamReq := models.CreateTeamRequest{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+		},
+	}
+
+	teamBody, _ := json.Marshal(teamReq)
+	resp, err := http.Post(server.URL+"/team/add", "application/json", bytes.NewBuffer(teamBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	prBody, _ := json.Marshal(models.CreatePRRequest{
+		PullRequestID:   "pr-1",
+		PullRequestName: "Metrics",
+		AuthorID:        "u1",
+	})
+	resp, err = http.Post(server.URL+"/pullRequest/create", "application/json", bytes.NewBuffer(prBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + "/metrics")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Contains(t, string(body), `prreviewer_http_requests_total{method="POST",route="/pullRequest/create",status="201"}`)
+	assert.Contains(t, string(body), "prreviewer_reviewer_assignments_total")
+	assert.Contains(t, string(body), "prreviewer_reviewers_per_pull_request_bucket")
+}
+
+func TestFairnessStatistics(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	teamReq := models.CreateTeamRequest{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+			{UserID: "u3", Username: "Charlie", IsActive: false},
+		},
+	}
+
+	teamBody, _ := json.Marshal(teamReq)
+	resp, err := http.Post(server.URL+"/team/add", "application/json", bytes.NewBuffer(teamBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	for i := 1; i <= 2; i++ {
+		prBody, _ := json.Marshal(models.CreatePRRequest{
+			PullRequestID:   fmt.Sprintf("pr-%d", i),
+			PullRequestName: fmt.Sprintf("Feature %d", i),
+			AuthorID:        "u1",
+		})
+		resp, err = http.Post(server.URL+"/pullRequest/create", "application/json", bytes.NewBuffer(prBody))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	resp, err = http.Get(server.URL + "/statistics/fairness?team_name=backend")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var fairness models.FairnessResponse
+	err = json.NewDecoder(resp.Body).Decode(&fairness)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Len(t, fairness.Teams, 1)
+	team := fairness.Teams[0]
+	assert.Equal(t, "backend", team.TeamName)
+	assert.Equal(t, 2, team.ActiveMembers)
+	assert.Equal(t, 2, team.TotalAssignments)
+	assert.InDelta(t, 0.5, team.Gini, 1e-9)
+	assert.Equal(t, "u2", team.MostLoaded[0].UserID)
+	assert.Equal(t, "u1", team.LeastLoaded[0].UserID)
+}
+
+func TestStatisticsAndExportFormats(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	teamReq := models.CreateTeamRequest{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+		},
+	}
+
+	teamBody, _ := json.Marshal(teamReq)
+	resp, err := http.Post(server.URL+"/team/add", "application/json", bytes.NewBuffer(teamBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	for i := 1; i <= 3; i++ {
+		prBody, _ := json.Marshal(models.CreatePRRequest{
+			PullRequestID:   fmt.Sprintf("pr-%d", i),
+			PullRequestName: fmt.Sprintf("Feature %d", i),
+			AuthorID:        "u1",
+		})
+		resp, err = http.Post(server.URL+"/pullRequest/create", "application/json", bytes.NewBuffer(prBody))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	get := func(path, accept string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", accept)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return resp
+	}
+
+	resp = get("/statistics", "text/csv")
+	assert.Equal(t, "text/csv", resp.Header.Get("Content-Type"))
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"kind", "id", "name", "count"}, records[0])
+	assert.Len(t, records, 1+2+3)
+
+	resp = get("/statistics", "application/x-ndjson")
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+	lines := 0
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		assert.Contains(t, []interface{}{"user", "pr"}, line["kind"])
+		lines++
+	}
+	resp.Body.Close()
+	assert.Equal(t, 5, lines)
+
+	resp = get("/export/prs", "application/x-ndjson")
+	var exported []models.PullRequest
+	scanner = bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var pr models.PullRequest
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &pr))
+		exported = append(exported, pr)
+	}
+	resp.Body.Close()
+	require.Len(t, exported, 3)
+	assert.Equal(t, "pr-1", exported[0].PullRequestID)
+	assert.Equal(t, []string{"u2"}, exported[0].AssignedReviewers)
+	require.Len(t, exported[0].Reviewers, 1)
+	assert.False(t, exported[0].Reviewers[0].AssignedAt.IsZero())
+
+	resp = get("/export/prs", "text/csv")
+	records, err = csv.NewReader(resp.Body).ReadAll()
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Len(t, records, 4)
+	assert.Equal(t, "u2", records[1][6])
+}
+
+func TestCollaborationGraph(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	teamReq := models.CreateTeamRequest{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+		},
+	}
+
+	teamBody, _ := json.Marshal(teamReq)
+	resp, err := http.Post(server.URL+"/team/add", "application/json", bytes.NewBuffer(teamBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	for _, prReq := range []models.CreatePRRequest{
+		{PullRequestID: "pr-1", PullRequestName: "First", AuthorID: "u1"},
+		{PullRequestID: "pr-2", PullRequestName: "Second", AuthorID: "u1"},
+		{PullRequestID: "pr-3", PullRequestName: "Third", AuthorID: "u2"},
+	} {
+		prBody, _ := json.Marshal(prReq)
+		resp, err = http.Post(server.URL+"/pullRequest/create", "application/json", bytes.NewBuffer(prBody))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	resp, err = http.Get(server.URL + "/statistics/graph?team_name=backend")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var graph models.CollaborationGraph
+	err = json.NewDecoder(resp.Body).Decode(&graph)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Len(t, graph.Nodes, 2)
+	assert.Equal(t, []models.GraphEdge{
+		{AuthorID: "u1", ReviewerID: "u2", Weight: 2},
+		{AuthorID: "u2", ReviewerID: "u1", Weight: 1},
+	}, graph.Edges)
+	assert.InDelta(t, 1.0, graph.Reciprocity, 1e-9)
+	require.Len(t, graph.BusFactor, 1)
+	assert.Equal(t, 1, graph.BusFactor[0].MinBusFactor)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/statistics/graph", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/vnd.graphviz")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Contains(t, string(body), "digraph reviews {")
+	assert.Contains(t, string(body), `"u1" -> "u2" [weight=2`)
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup, err := test.SetupTestDB(ctx)
+	require.NoError(t, err)
+	defer cleanup()
+
+	r := mux.NewRouter()
+	api := v1.NewAPI(repository.NewRepository(db), config.ServerConfig{}, config.AuthConfig{Enabled: true, BootstrapKey: "bootstrap-secret"}, config.FeaturesConfig{}, nil, nil)
+	api.RegisterHandlers(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	do := func(method, path, key string, body interface{}) *http.Response {
+		var buf bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&buf).Encode(body))
+		}
+		req, err := http.NewRequest(method, server.URL+path, &buf)
+		require.NoError(t, err)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := do(http.MethodGet, "/health", "", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = do(http.MethodGet, "/statistics", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = do(http.MethodGet, "/statistics", "wrong-key", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = do(http.MethodPost, "/apiKeys/create", "bootstrap-secret", models.CreateAPIKeyRequest{Name: "dashboards", Role: "read_only"})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var created struct {
+		APIKey models.APIKey `json:"api_key"`
+		Key    string        `json:"key"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	resp.Body.Close()
+	require.NotEmpty(t, created.Key)
+
+	resp = do(http.MethodGet, "/statistics", created.Key, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = do(http.MethodPost, "/team/add", created.Key, models.CreateTeamRequest{TeamName: "backend"})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = do(http.MethodPost, "/apiKeys/revoke", "bootstrap-secret", models.RevokeAPIKeyRequest{KeyID: created.APIKey.KeyID})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = do(http.MethodGet, "/statistics", created.Key, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup, err := test.SetupTestDB(ctx)
+	require.NoError(t, err)
+	defer cleanup()
+
+	r := mux.NewRouter()
+	api := v1.NewAPI(repository.NewRepository(db), config.ServerConfig{}, config.AuthConfig{}, config.FeaturesConfig{}, nil, nil)
+	api.RegisterHandlers(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	post := func(path, key string, body interface{}) (*http.Response, []byte) {
+		var buf bytes.Buffer
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+		req, err := http.NewRequest(http.MethodPost, server.URL+path, &buf)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, data
+	}
+
+	team := models.CreateTeamRequest{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+		},
+	}
+
+	first, firstBody := post("/team/add", "team-1", team)
+	require.Equal(t, http.StatusCreated, first.StatusCode)
+	assert.Empty(t, first.Header.Get("Idempotent-Replayed"))
+
+	replay, replayBody := post("/team/add", "team-1", team)
+	assert.Equal(t, http.StatusCreated, replay.StatusCode)
+	assert.Equal(t, "true", replay.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, firstBody, replayBody)
+
+	team.TeamName = "frontend"
+	reused, reusedBody := post("/team/add", "team-1", team)
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.StatusCode)
+	var errResp models.ErrorResponse
+	require.NoError(t, json.Unmarshal(reusedBody, &errResp))
+	assert.Equal(t, "IDEMPOTENCY_KEY_REUSED", errResp.Error.Code)
+
+	pr := models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Add feature", AuthorID: "u1"}
+	created, _ := post("/pullRequest/create", "pr-1", pr)
+	require.Equal(t, http.StatusCreated, created.StatusCode)
+
+	again, _ := post("/pullRequest/create", "pr-1", pr)
+	assert.Equal(t, http.StatusCreated, again.StatusCode)
+	assert.Equal(t, "true", again.Header.Get("Idempotent-Replayed"))
+
+	conflict, _ := post("/pullRequest/create", "pr-1-retry", pr)
+	assert.Equal(t, http.StatusConflict, conflict.StatusCode)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup, err := test.SetupTestDB(ctx)
+	require.NoError(t, err)
+	defer cleanup()
+
+	r := mux.NewRouter()
+	api := v1.NewAPI(repository.NewRepository(db), config.ServerConfig{RequestTimeout: time.Nanosecond}, config.AuthConfig{}, config.FeaturesConfig{}, nil, nil)
+	api.RegisterHandlers(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/statistics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+
+	var errResp models.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	assert.Equal(t, "TIMEOUT", errResp.Error.Code)
+}
+
+func TestRequestID(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup, err := test.SetupTestDB(ctx)
+	require.NoError(t, err)
+	defer cleanup()
+
+	r := mux.NewRouter()
+	api := v1.NewAPI(repository.NewRepository(db), config.ServerConfig{}, config.AuthConfig{}, config.FeaturesConfig{}, nil, nil)
+	api.RegisterHandlers(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	get := func(requestID string) (*http.Response, models.ErrorResponse) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/team/get?team_name=missing", nil)
+		require.NoError(t, err)
+		if requestID != "" {
+			req.Header.Set("X-Request-ID", requestID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var body models.ErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp, body
+	}
+
+	resp, body := get("client-id-1")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "TEAM_NOT_FOUND", body.Error.Code)
+	assert.Equal(t, "client-id-1", resp.Header.Get("X-Request-ID"))
+	assert.Equal(t, "client-id-1", body.Error.RequestID)
+
+	resp, body = get("")
+	generated := resp.Header.Get("X-Request-ID")
+	assert.Len(t, generated, 32)
+	assert.Equal(t, generated, body.Error.RequestID)
+
+	resp, _ = get("bad id with spaces")
+	assert.NotEqual(t, "bad id with spaces", resp.Header.Get("X-Request-ID"))
+}
+
+func TestInternalErrorIsNotLeaked(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup, err := test.SetupTestDB(ctx)
+	require.NoError(t, err)
+	defer cleanup()
+
+	r := mux.NewRouter()
+	api := v1.NewAPI(repository.NewRepository(db), config.ServerConfig{}, config.AuthConfig{}, config.FeaturesConfig{}, nil, nil)
+	api.RegisterHandlers(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	// Every query fails with "sql: database is closed" from here on.
+	require.NoError(t, db.Close())
+
+	resp, err := http.Get(server.URL + "/team/get?team_name=backend")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var body models.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, "INTERNAL_ERROR", body.Error.Code)
+	assert.Equal(t, "internal server error", body.Error.Message)
+	assert.NotEmpty(t, body.Error.RequestID)
+	assert.Equal(t, resp.Header.Get("X-Request-ID"), body.Error.RequestID)
+}
+
+func TestValidationErrors(t *testing.T) {
+	server, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	post := func(path, body string) (int, models.ErrorResponse) {
+		resp, err := http.Post(server.URL+path, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var errResp models.ErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+		return resp.StatusCode, errResp
+	}
+
+	status, body := post("/pullRequest/create", `{"pull_request_id": "pr-1", "pull_request_name": "Feature", "author_id": "u1", "reviewers": ["u2"]}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []models.FieldError{{Field: "reviewers", Message: "is not a known field"}}, body.Error.Details)
+
+	status, body = post("/pullRequest/create", `{"pull_request_id": "", "pull_request_name": "Feature", "author_id": "u1"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "INVALID_REQUEST", body.Error.Code)
+	assert.Equal(t, []models.FieldError{{Field: "pull_request_id", Message: "is required"}}, body.Error.Details)
+
+	status, body = post("/users/setIsActive", `{"user_id": "u1", "is_active": "yes"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []models.FieldError{{Field: "is_active", Message: "must be a boolean"}}, body.Error.Details)
+
+	status, body = post("/team/add", `{"team_name": "`+strings.Repeat("t", 101)+`", "members": []}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []models.FieldError{{Field: "team_name", Message: "must be at most 100 characters"}}, body.Error.Details)
+
+	status, body = post("/pullRequest/create", `{"pull_request_id": "pr-1", "pull_request_name": "Feature", "author_id": "missing"}`)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "USER_NOT_FOUND", body.Error.Code)
+
+	status, body = post("/pullRequest/merge", `{"pull_request_id": "missing"}`)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "PR_NOT_FOUND", body.Error.Code)
+}
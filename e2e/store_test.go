@@ -0,0 +1,29 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/milyrock/PR-Reviewer/internal/repository"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+	"github.com/milyrock/PR-Reviewer/internal/test"
+	"github.com/milyrock/PR-Reviewer/internal/test/storetest"
+	"github.com/stretchr/testify/require"
+)
+
+const truncateAll = `TRUNCATE teams, users, pull_requests, pr_reviewers, review_decisions, api_keys, idempotency_records CASCADE`
+
+func TestPostgresStoreConformance(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup, err := test.SetupTestDB(ctx)
+	require.NoError(t, err)
+	defer cleanup()
+
+	repo := repository.NewRepository(db)
+
+	storetest.Run(t, func(t *testing.T) service.Store {
+		_, err := db.Exec(truncateAll)
+		require.NoError(t, err)
+		return repo
+	})
+}
@@ -1,36 +1,42 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"os"
+	"strings"
+	"time"
 
-	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
 	"github.com/jmoiron/sqlx"
 	"github.com/milyrock/PR-Reviewer/internal/config"
 )
 
-func InitDB(cfg config.DatabaseConfig) (*sqlx.DB, error) {
-	dataSource := fmt.Sprintf(
-		"host=%s user=%s password=%s database=%s port=%s sslmode=disable",
-		cfg.Host,
-		cfg.Username,
-		cfg.Password,
-		cfg.Database,
-		cfg.Port,
-	)
-
-	db, err := sqlx.Open("pgx", dataSource)
+// InitDB connects to Postgres, waiting for it to accept connections as
+// configured by the connect_* settings, and applies the schema.
+func InitDB(ctx context.Context, cfg config.DatabaseConfig) (*sqlx.DB, error) {
+	connCfg, err := connConfig(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("create pool of connections to database: %w", err)
+		return nil, fmt.Errorf("parse database config: %w", err)
 	}
+	db := sqlx.NewDb(stdlib.OpenDB(*connCfg), "pgx")
 
-	if err = db.Ping(); err != nil {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	if err = waitForDB(ctx, db, cfg); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("connect to database: %w", err)
 	}
 
 	migrationFile := "./db/pr.sql"
 	if _, err := os.Stat(migrationFile); err == nil {
 		if err := initSchema(db, migrationFile); err != nil {
+			db.Close()
 			return nil, fmt.Errorf("failed to initialize schema: %w", err)
 		}
 	}
@@ -38,6 +44,57 @@ func InitDB(cfg config.DatabaseConfig) (*sqlx.DB, error) {
 	return db, nil
 }
 
+// dbKeepAlive matches the keep-alive of pgconn's default dialer.
+const dbKeepAlive = 5 * time.Minute
+
+// connConfig dials over cfg.Network. pgconn would otherwise pick the network
+// from the host alone, so a misconfigured host would silently connect over
+// the other one.
+func connConfig(cfg config.DatabaseConfig) (*pgx.ConnConfig, error) {
+	connCfg, err := pgx.ParseConfig(dataSourceName(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{KeepAlive: dbKeepAlive}
+	connCfg.DialFunc = func(ctx context.Context, _, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, cfg.Network, addr)
+	}
+
+	return connCfg, nil
+}
+
+// dataSourceName builds a keyword/value DSN. With the unix network Host is the
+// socket directory, which libpq-style DSNs recognise by the leading slash.
+//
+// The session time zone is pinned to UTC: date_trunc on timestamptz truncates
+// in the session zone, and the statistics buckets must match the UTC ones the
+// SQLite and in-memory stores compute.
+func dataSourceName(cfg config.DatabaseConfig) string {
+	params := []string{
+		"host=" + quoteDSNValue(cfg.Host),
+		"port=" + quoteDSNValue(cfg.Port),
+		"user=" + quoteDSNValue(cfg.Username),
+		"password=" + quoteDSNValue(cfg.Password),
+		"database=" + quoteDSNValue(cfg.Database),
+		"sslmode=" + quoteDSNValue(cfg.SSLMode),
+		"timezone='UTC'",
+	}
+
+	if cfg.SSLRootCert != "" {
+		params = append(params, "sslrootcert="+quoteDSNValue(cfg.SSLRootCert))
+	}
+	if cfg.StatementTimeout > 0 {
+		params = append(params, fmt.Sprintf("statement_timeout=%d", cfg.StatementTimeout.Milliseconds()))
+	}
+
+	return strings.Join(params, " ")
+}
+
+func quoteDSNValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
 func initSchema(db *sqlx.DB, migrationFile string) error {
 	sqlBytes, err := os.ReadFile(migrationFile)
 	if err != nil {
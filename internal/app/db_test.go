@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milyrock/PR-Reviewer/internal/config"
+)
+
+func TestDataSourceName(t *testing.T) {
+	dsn := dataSourceName(config.DatabaseConfig{
+		Host:             "db",
+		Port:             "5432",
+		Username:         "postgres",
+		Password:         `it's a \secret`,
+		Database:         "pr",
+		SSLMode:          "verify-full",
+		SSLRootCert:      "/etc/ssl/root.crt",
+		StatementTimeout: 25 * time.Second,
+	})
+
+	assert.Equal(t,
+		`host='db' port='5432' user='postgres' password='it\'s a \\secret' database='pr' `+
+			`sslmode='verify-full' timezone='UTC' sslrootcert='/etc/ssl/root.crt' statement_timeout=25000`,
+		dsn)
+}
+
+func TestConnConfigDialsConfiguredNetwork(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	connCfg, err := connConfig(config.DatabaseConfig{Host: "127.0.0.1", Port: "5432", Network: "tcp", SSLMode: "disable"})
+	require.NoError(t, err)
+
+	// pgconn passes the network it guessed from the host; the configured one
+	// wins.
+	conn, err := connCfg.DialFunc(context.Background(), "unix", listener.Addr().String())
+	require.NoError(t, err)
+	conn.Close()
+	assert.Equal(t, "tcp", conn.RemoteAddr().Network())
+}
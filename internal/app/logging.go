@@ -0,0 +1,30 @@
+package app
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/milyrock/PR-Reviewer/internal/config"
+	"github.com/milyrock/PR-Reviewer/internal/logging"
+)
+
+// SetupLogging installs the process logger. Records logged with a request
+// context carry the request ID and caller. Output of the standard log package
+// is routed through it as well.
+func SetupLogging(cfg config.LoggingConfig) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		level = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	if cfg.Format == "json" {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	}
+
+	slog.SetDefault(slog.New(logging.NewHandler(handler)))
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/config"
+)
+
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// waitForDB pings db until it answers, sleeping between attempts with a
+// backoff that doubles from cfg.ConnectBackoff up to cfg.ConnectMaxBackoff.
+// It gives up after cfg.ConnectTimeout in total, or when ctx is cancelled.
+func waitForDB(ctx context.Context, db pinger, cfg config.DatabaseConfig) error {
+	ctx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout)
+	defer cancel()
+
+	backoff := cfg.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			if attempt > 1 {
+				slog.InfoContext(ctx, "database is ready", "attempts", attempt)
+			}
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("database not ready after %d attempts: %w", attempt, err)
+		}
+
+		slog.WarnContext(ctx, "database not ready", "attempt", attempt, "error", err, "retry_in", backoff)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("database not ready after %d attempts: %w", attempt, err)
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > cfg.ConnectMaxBackoff {
+			backoff = cfg.ConnectMaxBackoff
+		}
+	}
+}
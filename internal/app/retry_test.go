@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milyrock/PR-Reviewer/internal/config"
+)
+
+type fakePinger struct {
+	failures int
+	attempts int
+}
+
+func (p *fakePinger) PingContext(ctx context.Context) error {
+	p.attempts++
+	if p.attempts <= p.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func retryConfig(timeout time.Duration) config.DatabaseConfig {
+	return config.DatabaseConfig{
+		ConnectTimeout:    timeout,
+		ConnectBackoff:    time.Millisecond,
+		ConnectMaxBackoff: 4 * time.Millisecond,
+	}
+}
+
+func TestWaitForDBRetriesUntilReady(t *testing.T) {
+	db := &fakePinger{failures: 3}
+
+	require.NoError(t, waitForDB(context.Background(), db, retryConfig(time.Second)))
+	assert.Equal(t, 4, db.attempts)
+}
+
+func TestWaitForDBGivesUpAfterDeadline(t *testing.T) {
+	db := &fakePinger{failures: 1 << 30}
+
+	err := waitForDB(context.Background(), db, retryConfig(20*time.Millisecond))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+	assert.Greater(t, db.attempts, 1)
+}
+
+func TestWaitForDBStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	db := &fakePinger{failures: 1 << 30}
+	require.Error(t, waitForDB(ctx, db, retryConfig(time.Minute)))
+	assert.Equal(t, 1, db.attempts)
+}
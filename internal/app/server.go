@@ -0,0 +1,22 @@
+package app
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/milyrock/PR-Reviewer/internal/config"
+)
+
+// NewServer builds the HTTP server for handler. The write timeout should stay
+// above server.request_timeout so handlers can still report TIMEOUT before
+// the connection is cut; config.Validate enforces that.
+func NewServer(cfg config.ServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              net.JoinHostPort(cfg.Host, cfg.Port),
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/milyrock/PR-Reviewer/internal/config"
+	"github.com/milyrock/PR-Reviewer/internal/repository/sqlite"
+)
+
+func InitSQLite(cfg config.SQLiteConfig) (*sqlx.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("create database directory: %w", err)
+	}
+
+	db, err := sqlite.Open(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+
+	migrationFile := "./db/sqlite.sql"
+	if _, err := os.Stat(migrationFile); err == nil {
+		if err := initSchema(db, migrationFile); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to initialize schema: %w", err)
+		}
+	}
+
+	return db, nil
+}
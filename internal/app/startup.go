@@ -0,0 +1,73 @@
+package app
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+const (
+	livenessPath  = "/health/live"
+	readinessPath = "/health/ready"
+
+	// legacyLivenessPath predates the live/ready split and is kept for
+	// existing probes.
+	legacyLivenessPath = "/health"
+)
+
+// Startup is the server handler while the application initialises. Until
+// Ready is called it answers the health endpoints itself (alive, not ready)
+// and rejects everything else with 503, so the listener can be up before the
+// database is.
+type Startup struct {
+	handler atomic.Pointer[http.Handler]
+}
+
+func NewStartup() *Startup {
+	return &Startup{}
+}
+
+// Ready hands every subsequent request to handler.
+func (s *Startup) Ready(handler http.Handler) {
+	s.handler.Store(&handler)
+}
+
+func (s *Startup) IsReady() bool {
+	return s.handler.Load() != nil
+}
+
+func (s *Startup) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if handler := s.handler.Load(); handler != nil {
+		(*handler).ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.URL.Path == livenessPath || r.URL.Path == legacyLivenessPath {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		return
+	}
+
+	w.Header().Set("Retry-After", "1")
+
+	if r.URL.Path == readinessPath {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "starting"})
+		return
+	}
+
+	var resp models.ErrorResponse
+	resp.Error.Code = "NOT_READY"
+	resp.Error.Message = "service is starting"
+	writeJSON(w, http.StatusServiceUnavailable, resp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Error("failed to encode response", "error", err)
+	}
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartupBeforeReady(t *testing.T) {
+	startup := NewStartup()
+
+	for path, status := range map[string]int{
+		"/health":       http.StatusOK,
+		"/health/live":  http.StatusOK,
+		"/health/ready": http.StatusServiceUnavailable,
+		"/team/get":     http.StatusServiceUnavailable,
+	} {
+		rec := httptest.NewRecorder()
+		startup.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, status, rec.Code, path)
+	}
+}
+
+func TestStartupDelegatesWhenReady(t *testing.T) {
+	startup := NewStartup()
+	startup.Ready(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	assert.True(t, startup.IsReady())
+
+	rec := httptest.NewRecorder()
+	startup.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+}
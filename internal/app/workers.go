@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+// Workers runs periodic background jobs. Every job gets a context that is
+// cancelled by Stop, so a job interrupted mid-query rolls back instead of
+// outliving the database connection. Each run is recorded as a heartbeat for
+// the readiness probe.
+type Workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs []*models.WorkerHeartbeat
+}
+
+func NewWorkers() *Workers {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Workers{ctx: ctx, cancel: cancel}
+}
+
+// Every runs fn each interval until Stop is called. Errors are logged and the
+// job keeps its schedule. A non-positive interval disables the job.
+func (w *Workers) Every(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	if interval <= 0 {
+		slog.Info("worker disabled", "worker", name)
+		return
+	}
+
+	job := &models.WorkerHeartbeat{Name: name, Interval: interval, StartedAt: time.Now()}
+
+	w.mu.Lock()
+	w.jobs = append(w.jobs, job)
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-ticker.C:
+				err := fn(w.ctx)
+				if err != nil && w.ctx.Err() == nil {
+					slog.Error("worker failed", "worker", name, "error", err)
+				}
+				w.beat(job, err)
+			}
+		}
+	}()
+}
+
+func (w *Workers) beat(job *models.WorkerHeartbeat, err error) {
+	now := time.Now()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	job.LastRunAt = &now
+	job.LastError = ""
+	if err != nil {
+		job.LastError = err.Error()
+	}
+}
+
+// Heartbeats returns a snapshot of every scheduled job.
+func (w *Workers) Heartbeats() []models.WorkerHeartbeat {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	heartbeats := make([]models.WorkerHeartbeat, 0, len(w.jobs))
+	for _, job := range w.jobs {
+		heartbeat := *job
+		if job.LastRunAt != nil {
+			lastRunAt := *job.LastRunAt
+			heartbeat.LastRunAt = &lastRunAt
+		}
+		heartbeats = append(heartbeats, heartbeat)
+	}
+
+	return heartbeats
+}
+
+// Stop cancels all jobs and waits for them to return, or for ctx to expire.
+func (w *Workers) Stop(ctx context.Context) error {
+	w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkersStopCancelsRunningJob(t *testing.T) {
+	workers := NewWorkers()
+
+	var runs atomic.Int32
+	started := make(chan struct{}, 1)
+	workers.Every("test", time.Millisecond, func(ctx context.Context) error {
+		runs.Add(1)
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, workers.Stop(ctx))
+	assert.Equal(t, int32(1), runs.Load())
+}
+
+func TestWorkersStopHonoursDeadline(t *testing.T) {
+	workers := NewWorkers()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	started := make(chan struct{}, 1)
+	workers.Every("stuck", time.Millisecond, func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return nil
+	})
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, workers.Stop(ctx), context.DeadlineExceeded)
+}
+
+func TestWorkersDisabledInterval(t *testing.T) {
+	workers := NewWorkers()
+	workers.Every("off", 0, func(ctx context.Context) error {
+		t.Error("disabled job ran")
+		return nil
+	})
+
+	require.NoError(t, workers.Stop(context.Background()))
+}
+
+func TestWorkersHeartbeats(t *testing.T) {
+	workers := NewWorkers()
+
+	ran := make(chan struct{})
+	var once atomic.Bool
+	workers.Every("beat", time.Millisecond, func(ctx context.Context) error {
+		if once.CompareAndSwap(false, true) {
+			close(ran)
+		}
+		return errors.New("boom")
+	})
+
+	<-ran
+	require.Eventually(t, func() bool {
+		heartbeats := workers.Heartbeats()
+		return len(heartbeats) == 1 && heartbeats[0].LastRunAt != nil
+	}, time.Second, time.Millisecond)
+
+	heartbeat := workers.Heartbeats()[0]
+	assert.Equal(t, "beat", heartbeat.Name)
+	assert.Equal(t, time.Millisecond, heartbeat.Interval)
+	assert.Equal(t, "boom", heartbeat.LastError)
+
+	require.NoError(t, workers.Stop(context.Background()))
+}
@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/milyrock/PR-Reviewer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://portal.example.com"
+	testAudience = "pr-reviewer"
+)
+
+func encodeInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func writeJWKS(t *testing.T, keys map[string]crypto.PublicKey) []byte {
+	t.Helper()
+
+	var set struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	for kid, key := range keys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, map[string]string{
+				"kty": "RSA", "kid": kid, "use": "sig",
+				"n": encodeInt(k.N), "e": encodeInt(big.NewInt(int64(k.E))),
+			})
+		case *ecdsa.PublicKey:
+			set.Keys = append(set.Keys, map[string]string{
+				"kty": "EC", "kid": kid, "crv": "P-256",
+				"x": encodeInt(k.X), "y": encodeInt(k.Y),
+			})
+		}
+	}
+
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+	return data
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key crypto.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "u1",
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"admin"},
+	}
+}
+
+func TestVerifierFromFile(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, writeJWKS(t, map[string]crypto.PublicKey{
+		"rsa-1": &rsaKey.PublicKey,
+		"ec-1":  &ecKey.PublicKey,
+	}), 0o600))
+
+	v, err := NewVerifier(config.JWTConfig{
+		JWKSFile:  path,
+		Issuer:    testIssuer,
+		Audience:  testAudience,
+		RoleClaim: "roles",
+	})
+	require.NoError(t, err)
+
+	claims, err := v.Verify(sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "u1", claims.UserID)
+	assert.Equal(t, []string{"admin"}, claims.Roles)
+
+	claims, err = v.Verify(sign(t, jwt.SigningMethodES256, "ec-1", ecKey, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "u1", claims.UserID)
+
+	tests := []struct {
+		name   string
+		mutate func(jwt.MapClaims)
+		kid    string
+	}{
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, "rsa-1"},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "other" }, "rsa-1"},
+		{"missing issuer", func(c jwt.MapClaims) { delete(c, "iss") }, "rsa-1"},
+		{"missing audience", func(c jwt.MapClaims) { delete(c, "aud") }, "rsa-1"},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }, "rsa-1"},
+		{"missing exp", func(c jwt.MapClaims) { delete(c, "exp") }, "rsa-1"},
+		{"missing subject", func(c jwt.MapClaims) { delete(c, "sub") }, "rsa-1"},
+		{"unknown kid", func(c jwt.MapClaims) {}, "rsa-2"},
+		{"key mismatch", func(c jwt.MapClaims) {}, "ec-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.mutate(claims)
+			_, err := v.Verify(sign(t, jwt.SigningMethodRS256, tt.kid, rsaKey, claims))
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestVerifierRejectsHMAC(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, writeJWKS(t, map[string]crypto.PublicKey{
+		"rsa-1": &rsaKey.PublicKey,
+	}), 0o600))
+
+	v, err := NewVerifier(config.JWTConfig{JWKSFile: path, Issuer: testIssuer, Audience: testAudience})
+	require.NoError(t, err)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+	token.Header["kid"] = "rsa-1"
+	signed, err := token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	_, err = v.Verify(signed)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifierFromURLRefreshesOnUnknownKid(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwks := writeJWKS(t, map[string]crypto.PublicKey{"old": &oldKey.PublicKey})
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		_, _ = w.Write(jwks)
+	}))
+	defer server.Close()
+
+	v, err := NewVerifier(config.JWTConfig{JWKSURL: server.URL, Issuer: testIssuer, Audience: testAudience})
+	require.NoError(t, err)
+	assert.Equal(t, 1, fetches)
+
+	_, err = v.Verify(sign(t, jwt.SigningMethodES256, "old", oldKey, validClaims()))
+	require.NoError(t, err)
+
+	jwks = writeJWKS(t, map[string]crypto.PublicKey{"old": &oldKey.PublicKey, "new": &newKey.PublicKey})
+	v.fetchedAt = time.Now().Add(-2 * jwksRefreshBackoff)
+
+	_, err = v.Verify(sign(t, jwt.SigningMethodES256, "new", newKey, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetches)
+}
+
+func TestNewVerifierRequiresOneSource(t *testing.T) {
+	_, err := NewVerifier(config.JWTConfig{})
+	assert.Error(t, err)
+
+	_, err = NewVerifier(config.JWTConfig{JWKSFile: "a", JWKSURL: "b"})
+	assert.Error(t, err)
+}
+
+func TestNewVerifierRequiresIssuerAndAudience(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, writeJWKS(t, map[string]crypto.PublicKey{
+		"rsa-1": &rsaKey.PublicKey,
+	}), 0o600))
+
+	for _, cfg := range []config.JWTConfig{
+		{JWKSFile: path},
+		{JWKSFile: path, Issuer: testIssuer},
+		{JWKSFile: path, Audience: testAudience},
+	} {
+		_, err := NewVerifier(cfg)
+		assert.Error(t, err, "issuer %q, audience %q", cfg.Issuer, cfg.Audience)
+	}
+}
@@ -2,14 +2,56 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Storage  StorageConfig  `yaml:"storage"`
 	Database DatabaseConfig `yaml:"postgres"`
+	Auth     AuthConfig     `yaml:"auth"`
+	Logging  LoggingConfig  `yaml:"logging"`
+	Features FeaturesConfig `yaml:"features"`
+	Workers  WorkersConfig  `yaml:"workers"`
 }
 
+type ServerConfig struct {
+	Host              string        `yaml:"host"`
+	Port              string        `yaml:"port"`
+	RequestTimeout    time.Duration `yaml:"request_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+}
+
+type WorkersConfig struct {
+	IdempotencyPurgeInterval time.Duration `yaml:"idempotency_purge_interval"`
+}
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// StorageConfig selects the backend. Driver is DriverPostgres or
+// DriverSQLite; the postgres section is only read for the former.
+type StorageConfig struct {
+	Driver string       `yaml:"driver"`
+	SQLite SQLiteConfig `yaml:"sqlite"`
+}
+
+type SQLiteConfig struct {
+	Path string `yaml:"path"`
+}
+
+// DatabaseConfig describes the Postgres connection. Network is "tcp" or
+// "unix"; for "unix" Host is the directory holding the server socket. At
+// startup the connection is retried for up to ConnectTimeout, with a backoff
+// doubling from ConnectBackoff to ConnectMaxBackoff.
 type DatabaseConfig struct {
 	Database string `yaml:"database"`
 	Host     string `yaml:"host"`
@@ -17,10 +59,103 @@ type DatabaseConfig struct {
 	Password string `yaml:"password"`
 	Network  string `yaml:"network"`
 	Port     string `yaml:"port"`
+
+	SSLMode     string `yaml:"sslmode"`
+	SSLRootCert string `yaml:"sslrootcert"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+
+	StatementTimeout time.Duration `yaml:"statement_timeout"`
+
+	ConnectTimeout    time.Duration `yaml:"connect_timeout"`
+	ConnectBackoff    time.Duration `yaml:"connect_backoff"`
+	ConnectMaxBackoff time.Duration `yaml:"connect_max_backoff"`
+}
+
+type AuthConfig struct {
+	Enabled      bool      `yaml:"enabled"`
+	BootstrapKey string    `yaml:"bootstrap_key"`
+	JWT          JWTConfig `yaml:"jwt"`
 }
 
+type JWTConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	JWKSFile    string `yaml:"jwks_file"`
+	JWKSURL     string `yaml:"jwks_url"`
+	Issuer      string `yaml:"issuer"`
+	Audience    string `yaml:"audience"`
+	UserClaim   string `yaml:"user_claim"`
+	RoleClaim   string `yaml:"role_claim"`
+	AdminRole   string `yaml:"admin_role"`
+	DefaultRole string `yaml:"default_role"`
+}
+
+// LoggingConfig sets the level (debug, info, warn, error) and format (text or
+// json) of the process logger.
+type LoggingConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// FeaturesConfig switches optional parts of the API. The zero value keeps
+// everything enabled.
+type FeaturesConfig struct {
+	DisableMetrics bool          `yaml:"disable_metrics"`
+	DisableExport  bool          `yaml:"disable_export"`
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl"`
+}
+
+// Default returns the configuration used for every setting the file and the
+// environment leave unset.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:              "8080",
+			RequestTimeout:    30 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      40 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Storage: StorageConfig{
+			Driver: DriverPostgres,
+			SQLite: SQLiteConfig{Path: "./data/pr-reviewer.db"},
+		},
+		Database: DatabaseConfig{
+			Network:         "tcp",
+			Port:            "5432",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+
+			ConnectTimeout:    time.Minute,
+			ConnectBackoff:    500 * time.Millisecond,
+			ConnectMaxBackoff: 5 * time.Second,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Features: FeaturesConfig{
+			IdempotencyTTL: 24 * time.Hour,
+		},
+		Workers: WorkersConfig{
+			IdempotencyPurgeInterval: 10 * time.Minute,
+		},
+	}
+}
+
+// ReadConfig loads the file at path on top of Default, expanding ${VAR}
+// references, then applies PRR_* environment overrides and validates the
+// result.
 func ReadConfig(path string) (*Config, error) {
-	var config Config
+	config := Default()
 
 	file, err := os.ReadFile(path)
 	if err != nil {
@@ -34,5 +169,13 @@ func ReadConfig(path string) (*Config, error) {
 		return nil, err
 	}
 
+	if err := applyEnv(&config, os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const minimalConfig = `
+postgres:
+  host: localhost
+  database: pr
+  username: postgres
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestReadConfigAppliesDefaults(t *testing.T) {
+	cfg, err := ReadConfig(writeConfig(t, minimalConfig))
+	require.NoError(t, err)
+
+	assert.Equal(t, "8080", cfg.Server.Port)
+	assert.Equal(t, 30*time.Second, cfg.Server.RequestTimeout)
+	assert.Equal(t, DriverPostgres, cfg.Storage.Driver)
+	assert.Equal(t, "5432", cfg.Database.Port)
+	assert.Equal(t, "disable", cfg.Database.SSLMode)
+	assert.Equal(t, 25, cfg.Database.MaxOpenConns)
+	assert.Equal(t, "info", cfg.Logging.Level)
+	assert.Equal(t, 24*time.Hour, cfg.Features.IdempotencyTTL)
+}
+
+func TestReadConfigKeepsDefaultForEmptyExpansion(t *testing.T) {
+	t.Setenv("PRR_TEST_UNSET_DRIVER", "")
+
+	cfg, err := ReadConfig(writeConfig(t, minimalConfig+`
+storage:
+  driver: ${PRR_TEST_UNSET_DRIVER}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, DriverPostgres, cfg.Storage.Driver)
+}
+
+func TestReadConfigEnvOverrides(t *testing.T) {
+	t.Setenv("PRR_SERVER_PORT", "9090")
+	t.Setenv("PRR_SERVER_REQUEST_TIMEOUT", "5s")
+	t.Setenv("PRR_POSTGRES_MAX_OPEN_CONNS", "7")
+	t.Setenv("PRR_POSTGRES_MAX_IDLE_CONNS", "3")
+	t.Setenv("PRR_AUTH_JWT_ISSUER", "https://issuer.example")
+	t.Setenv("PRR_FEATURES_DISABLE_EXPORT", "true")
+
+	cfg, err := ReadConfig(writeConfig(t, minimalConfig+`
+server:
+  port: 8081
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "9090", cfg.Server.Port)
+	assert.Equal(t, 5*time.Second, cfg.Server.RequestTimeout)
+	assert.Equal(t, 7, cfg.Database.MaxOpenConns)
+	assert.Equal(t, 3, cfg.Database.MaxIdleConns)
+	assert.Equal(t, "https://issuer.example", cfg.Auth.JWT.Issuer)
+	assert.True(t, cfg.Features.DisableExport)
+}
+
+func TestReadConfigRejectsMalformedEnvOverride(t *testing.T) {
+	t.Setenv("PRR_POSTGRES_MAX_OPEN_CONNS", "many")
+
+	_, err := ReadConfig(writeConfig(t, minimalConfig))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "PRR_POSTGRES_MAX_OPEN_CONNS")
+}
+
+func TestValidateReportsEverySetting(t *testing.T) {
+	cfg := Default()
+	cfg.Server.Port = "http"
+	cfg.Server.WriteTimeout = 10 * time.Second
+	cfg.Database.Network = "udp"
+	cfg.Database.SSLMode = "sometimes"
+	cfg.Database.MaxIdleConns = 50
+	cfg.Logging.Format = "xml"
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	for _, path := range []string{
+		"server.port",
+		"server.write_timeout",
+		"postgres.host",
+		"postgres.network",
+		"postgres.sslmode",
+		"postgres.max_idle_conns",
+		"logging.format",
+	} {
+		assert.Contains(t, err.Error(), path+":")
+	}
+}
+
+func TestValidateSQLiteSkipsPostgres(t *testing.T) {
+	cfg := Default()
+	cfg.Storage.Driver = DriverSQLite
+
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Storage.Driver = "mysql"
+	assert.ErrorContains(t, cfg.Validate(), "storage.driver")
+}
+
+func TestValidateUnixSocketHost(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Host = "db"
+	cfg.Database.Database = "pr"
+	cfg.Database.Username = "postgres"
+	cfg.Database.Network = "unix"
+
+	assert.ErrorContains(t, cfg.Validate(), "postgres.host")
+
+	cfg.Database.Host = "/var/run/postgresql"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Database.Network = "tcp"
+	assert.ErrorContains(t, cfg.Validate(), "postgres.host")
+}
+
+func TestValidateJWT(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Host = "db"
+	cfg.Database.Database = "pr"
+	cfg.Database.Username = "postgres"
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWT.Enabled = true
+	cfg.Auth.JWT.JWKSURL = "https://issuer.example/jwks"
+
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "auth.jwt.issuer:")
+	assert.ErrorContains(t, err, "auth.jwt.audience:")
+
+	cfg.Auth.JWT.Issuer = "https://issuer.example"
+	cfg.Auth.JWT.Audience = "pr-reviewer"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Auth.Enabled = false
+	assert.ErrorContains(t, cfg.Validate(), "auth.jwt.enabled:")
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix starts the name of every environment override. The rest of the
+// name is the yaml path of the setting in upper case joined by underscores,
+// e.g. PRR_SERVER_PORT or PRR_POSTGRES_MAX_OPEN_CONNS.
+const EnvPrefix = "PRR"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv overrides every field of cfg for which lookup finds a variable.
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	return applyEnvValue(reflect.ValueOf(cfg).Elem(), EnvPrefix, lookup)
+}
+
+func applyEnvValue(v reflect.Value, name string, lookup func(string) (string, bool)) error {
+	if v.Kind() == reflect.Struct {
+		for i := 0; i < v.NumField(); i++ {
+			tag, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+			if tag == "" || tag == "-" {
+				continue
+			}
+
+			if err := applyEnvValue(v.Field(i), name+"_"+strings.ToUpper(tag), lookup); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	raw, ok := lookup(name)
+	if !ok {
+		return nil
+	}
+
+	if err := setFromString(v, raw); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func setFromString(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(int64(n))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+
+	return nil
+}
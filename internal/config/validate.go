@@ -0,0 +1,169 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+var sslModes = map[string]bool{
+	"disable":     true,
+	"allow":       true,
+	"prefer":      true,
+	"require":     true,
+	"verify-ca":   true,
+	"verify-full": true,
+}
+
+var logLevels = map[string]bool{
+	"debug": true,
+	"info":  true,
+	"warn":  true,
+	"error": true,
+}
+
+// Validate reports every invalid setting at once, each prefixed with its yaml
+// path, so a misconfigured deployment fails at startup with a clear message.
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(path, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+
+	validatePort(add, "server.port", c.Server.Port)
+	for _, setting := range []struct {
+		path  string
+		value time.Duration
+	}{
+		{"server.request_timeout", c.Server.RequestTimeout},
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+	} {
+		if setting.value < 0 {
+			add(setting.path, "must not be negative")
+		}
+	}
+	if c.Server.WriteTimeout > 0 && c.Server.RequestTimeout > 0 && c.Server.WriteTimeout <= c.Server.RequestTimeout {
+		add("server.write_timeout", "must be greater than server.request_timeout (%s)", c.Server.RequestTimeout)
+	}
+
+	switch c.Storage.Driver {
+	case DriverPostgres:
+		c.Database.validate(add)
+	case DriverSQLite:
+		if c.Storage.SQLite.Path == "" {
+			add("storage.sqlite.path", "is required")
+		}
+	default:
+		add("storage.driver", "must be %q or %q, got %q", DriverPostgres, DriverSQLite, c.Storage.Driver)
+	}
+
+	if c.Auth.JWT.Enabled {
+		if c.Auth.JWT.JWKSFile == "" && c.Auth.JWT.JWKSURL == "" {
+			add("auth.jwt", "jwks_file or jwks_url is required when jwt is enabled")
+		}
+		// Tokens from the same identity provider issued for other apps
+		// must not be accepted.
+		if c.Auth.JWT.Issuer == "" {
+			add("auth.jwt.issuer", "is required when jwt is enabled")
+		}
+		if c.Auth.JWT.Audience == "" {
+			add("auth.jwt.audience", "is required when jwt is enabled")
+		}
+		if !c.Auth.Enabled {
+			add("auth.jwt.enabled", "requires auth.enabled, otherwise bearer tokens are ignored")
+		}
+	}
+
+	if !logLevels[c.Logging.Level] {
+		add("logging.level", "must be one of debug, info, warn, error, got %q", c.Logging.Level)
+	}
+	if c.Logging.Format != "text" && c.Logging.Format != "json" {
+		add("logging.format", "must be text or json, got %q", c.Logging.Format)
+	}
+
+	if c.Features.IdempotencyTTL <= 0 {
+		add("features.idempotency_ttl", "must be positive")
+	}
+	if c.Workers.IdempotencyPurgeInterval < 0 {
+		add("workers.idempotency_purge_interval", "must not be negative")
+	}
+
+	return errors.Join(errs...)
+}
+
+func (d *DatabaseConfig) validate(add func(path, format string, args ...interface{})) {
+	for _, setting := range []struct {
+		path  string
+		value string
+	}{
+		{"postgres.host", d.Host},
+		{"postgres.database", d.Database},
+		{"postgres.username", d.Username},
+	} {
+		if setting.value == "" {
+			add(setting.path, "is required")
+		}
+	}
+
+	switch d.Network {
+	case "tcp":
+		if filepath.IsAbs(d.Host) {
+			add("postgres.host", "is a socket directory but network is tcp, got %q", d.Host)
+		}
+	case "unix":
+		if d.Host != "" && !filepath.IsAbs(d.Host) {
+			add("postgres.host", "must be the absolute socket directory when network is unix, got %q", d.Host)
+		}
+	default:
+		add("postgres.network", "must be tcp or unix, got %q", d.Network)
+	}
+	validatePort(add, "postgres.port", d.Port)
+
+	if !sslModes[d.SSLMode] {
+		add("postgres.sslmode", "unknown mode %q", d.SSLMode)
+	}
+	if d.SSLRootCert != "" && d.SSLMode == "disable" {
+		add("postgres.sslrootcert", "is set but sslmode is disable")
+	}
+
+	if d.MaxOpenConns < 0 {
+		add("postgres.max_open_conns", "must not be negative")
+	}
+	if d.MaxIdleConns < 0 {
+		add("postgres.max_idle_conns", "must not be negative")
+	}
+	if d.MaxOpenConns > 0 && d.MaxIdleConns > d.MaxOpenConns {
+		add("postgres.max_idle_conns", "must not exceed max_open_conns (%d)", d.MaxOpenConns)
+	}
+	if d.ConnMaxLifetime < 0 {
+		add("postgres.conn_max_lifetime", "must not be negative")
+	}
+	if d.ConnMaxIdleTime < 0 {
+		add("postgres.conn_max_idle_time", "must not be negative")
+	}
+	if d.StatementTimeout < 0 {
+		add("postgres.statement_timeout", "must not be negative")
+	}
+	if d.ConnectTimeout <= 0 {
+		add("postgres.connect_timeout", "must be positive")
+	}
+	if d.ConnectBackoff <= 0 {
+		add("postgres.connect_backoff", "must be positive")
+	}
+	if d.ConnectMaxBackoff < d.ConnectBackoff {
+		add("postgres.connect_max_backoff", "must not be less than connect_backoff (%s)", d.ConnectBackoff)
+	}
+}
+
+func validatePort(add func(path, format string, args ...interface{}), path, port string) {
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		add(path, "must be a port number between 1 and 65535, got %q", port)
+	}
+}
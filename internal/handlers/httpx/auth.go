@@ -0,0 +1,79 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+// Principal is the authenticated caller. UserID is set only for callers
+// authenticated as a person (JWT), not for API keys.
+type Principal struct {
+	ID     string
+	Role   string
+	UserID string
+}
+
+type principalKey struct{}
+
+var roleLevels = map[string]int{
+	models.RoleReadOnly:    1,
+	models.RoleIntegration: 2,
+	models.RoleAdmin:       3,
+}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// CallerUserID returns the user_id of a caller authenticated as a person, or
+// an empty string for API keys and unauthenticated requests.
+func CallerUserID(r *http.Request) string {
+	if p := PrincipalFromContext(r.Context()); p != nil {
+		return p.UserID
+	}
+	return ""
+}
+
+// CanActAsUser reports whether the caller may act on behalf of userID. Only
+// person callers are restricted; API keys and admins may act for anyone.
+func CanActAsUser(r *http.Request, userID string) bool {
+	p := PrincipalFromContext(r.Context())
+	if p == nil || p.UserID == "" || p.Role == models.RoleAdmin {
+		return true
+	}
+	return p.UserID == userID
+}
+
+// Authorizer guards handlers with a minimum caller role. The principal is set
+// by the authentication middleware; when authentication is disabled every
+// request passes.
+type Authorizer struct {
+	Enabled bool
+}
+
+func (a Authorizer) Require(role string, h http.HandlerFunc) http.HandlerFunc {
+	if !a.Enabled {
+		return h
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := PrincipalFromContext(r.Context())
+		if p == nil {
+			WriteError(w, http.StatusUnauthorized, ErrorCodeUnauthorized, ErrorMsgUnauthorized)
+			return
+		}
+		if roleLevels[p.Role] < roleLevels[role] {
+			WriteError(w, http.StatusForbidden, ErrorCodeForbidden, ErrorMsgForbidden)
+			return
+		}
+
+		h(w, r)
+	}
+}
@@ -0,0 +1,23 @@
+package httpx
+
+// Error codes and messages shared by the versioned handler packages. Messages
+// specific to one API version stay in that package.
+const (
+	ErrorCodeInvalidRequest = "INVALID_REQUEST"
+	ErrorCodeInternalError  = "INTERNAL_ERROR"
+	ErrorCodeUnauthorized   = "UNAUTHORIZED"
+	ErrorCodeForbidden      = "FORBIDDEN"
+)
+
+const (
+	ErrorMsgInvalidRequestBody = "invalid request body"
+	ErrorMsgInvalidStatus      = "status must be OPEN or MERGED"
+	ErrorMsgInvalidOrder       = "order must be asc or desc"
+	ErrorMsgInvalidLimit       = "limit must be a positive integer"
+	ErrorMsgInvalidTime        = "time parameters must be in RFC3339 format"
+	ErrorMsgUnauthorized       = "missing or invalid credentials"
+	ErrorMsgForbidden          = "insufficient role for this operation"
+	ErrorMsgInternalError      = "internal server error"
+	ErrorMsgReassignSelfOnly   = "users can only reassign themselves"
+	ErrorMsgReviewSelfOnly     = "users can only submit their own review"
+)
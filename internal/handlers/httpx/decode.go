@@ -0,0 +1,144 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+)
+
+var errInvalidRequestBody = &service.Error{
+	Code:    ErrorCodeInvalidRequest,
+	Status:  http.StatusBadRequest,
+	Message: ErrorMsgInvalidRequestBody,
+}
+
+// InvalidParam reports a malformed query parameter.
+func InvalidParam(message string) error {
+	return &service.Error{Code: ErrorCodeInvalidRequest, Status: http.StatusBadRequest, Message: message}
+}
+
+// DecodeJSON reads the request body into dst. Unknown fields are rejected, so
+// that a misspelt field is reported instead of silently left at its zero value.
+// Failures are returned as domain errors for HandleServiceError.
+func DecodeJSON(r *http.Request, dst interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(dst)
+	if err == nil {
+		return nil
+	}
+
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return service.NewValidationError(models.FieldError{Field: strings.Trim(field, `"`), Message: "is not a known field"})
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return service.NewValidationError(models.FieldError{Field: fieldPath(typeErr.Field), Message: "must be " + jsonKind(typeErr.Type.Kind())})
+	}
+
+	return errInvalidRequestBody
+}
+
+// fieldPath rewrites encoding/json's "members.0.is_active" into the
+// "members[0].is_active" form used by the validation errors.
+func fieldPath(field string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(part); err == nil {
+			b.WriteString("[" + part + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+func jsonKind(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	default:
+		return "a number"
+	}
+}
+
+// ParseTimeParam reads an optional RFC3339 query parameter.
+func ParseTimeParam(query url.Values, name string) (*time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// ParsePRListFilter reads the pull request list filters (status, author_id,
+// reviewer_id, team_name, name, order, limit and the created/merged time
+// bounds) from query parameters.
+func ParsePRListFilter(query url.Values) (models.PRListFilter, error) {
+	filter := models.PRListFilter{
+		Status:     query.Get("status"),
+		AuthorID:   query.Get("author_id"),
+		ReviewerID: query.Get("reviewer_id"),
+		TeamName:   query.Get("team_name"),
+		Name:       query.Get("name"),
+	}
+
+	if filter.Status != "" && filter.Status != "OPEN" && filter.Status != "MERGED" {
+		return filter, InvalidParam(ErrorMsgInvalidStatus)
+	}
+
+	switch query.Get("order") {
+	case "", "desc":
+	case "asc":
+		filter.SortAsc = true
+	default:
+		return filter, InvalidParam(ErrorMsgInvalidOrder)
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return filter, InvalidParam(ErrorMsgInvalidLimit)
+		}
+		filter.Limit = n
+	}
+
+	var err error
+	for param, dst := range map[string]**time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+		"merged_from":  &filter.MergedFrom,
+		"merged_to":    &filter.MergedTo,
+	} {
+		if *dst, err = ParseTimeParam(query, param); err != nil {
+			return filter, InvalidParam(ErrorMsgInvalidTime)
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,82 @@
+// Package httpx holds the HTTP plumbing shared by every API version: the
+// error envelope, request decoding, query parsing and the caller's principal
+// and role checks. The versioned handler packages build their routes on it so
+// that v1 and v2 answer errors and enforce roles the same way.
+package httpx
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/logging"
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+)
+
+const (
+	RequestIDHeader       = "X-Request-ID"
+	generatedRequestIDLen = 16
+)
+
+// NewRequestID returns a random ID for a request that did not bring a usable
+// one.
+func NewRequestID() string {
+	b := make([]byte, generatedRequestIDLen)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to
+		// something unique enough to correlate log lines.
+		return time.Now().UTC().Format("20060102T150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
+
+// HandleServiceError answers with the code, status and message of a domain
+// error. Anything else is an internal failure: it is logged with the request
+// ID and the client only gets a generic message and that ID to quote.
+func HandleServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	if repository.IsTimeout(err) {
+		err = service.ErrTimeout
+	}
+
+	var domainErr *service.Error
+	if errors.As(err, &domainErr) {
+		WriteError(w, domainErr.Status, domainErr.Code, domainErr.Message, domainErr.Details...)
+		return
+	}
+
+	ctx := r.Context()
+	if logging.RequestID(ctx) == "" {
+		// Outside the request logging middleware, e.g. a handler mounted on
+		// its own router.
+		requestID := NewRequestID()
+		w.Header().Set(RequestIDHeader, requestID)
+		ctx = logging.WithRequestID(ctx, requestID)
+	}
+
+	slog.ErrorContext(ctx, "internal error", "method", r.Method, "path", r.URL.Path, "error", err)
+	WriteError(w, http.StatusInternalServerError, ErrorCodeInternalError, ErrorMsgInternalError)
+}
+
+// WriteError echoes the request ID of the response, so that a client
+// reporting an error can be matched with the server log.
+func WriteError(w http.ResponseWriter, statusCode int, code, message string, details ...models.FieldError) {
+	requestID := w.Header().Get(RequestIDHeader)
+
+	var resp models.ErrorResponse
+	resp.Error.Code = code
+	resp.Error.Message = message
+	resp.Error.RequestID = requestID
+	resp.Error.Details = details
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("failed to encode response", "error", err, logging.RequestIDKey, requestID)
+	}
+}
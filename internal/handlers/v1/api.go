@@ -1,54 +1,130 @@
 package v1
 
 import (
+	"time"
+
 	"github.com/gorilla/mux"
-	"github.com/milyrock/PR-Reviewer/internal/repository"
+	"github.com/milyrock/PR-Reviewer/internal/auth"
+	"github.com/milyrock/PR-Reviewer/internal/config"
+	"github.com/milyrock/PR-Reviewer/internal/metrics"
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type API struct {
-	teamHandler       *TeamHandler
-	userHandler       *UserHandler
-	prHandler         *PRHandler
-	statisticsHandler *StatisticsHandler
+	healthHandler      *HealthHandler
+	teamHandler        *TeamHandler
+	userHandler        *UserHandler
+	prHandler          *PRHandler
+	statisticsHandler  *StatisticsHandler
+	apiKeyHandler      *APIKeyHandler
+	idempotencyService *service.IdempotencyService
+	requestTimeout     time.Duration
+	authEnabled        bool
+	jwtVerifier        *auth.Verifier
+	jwtCfg             config.JWTConfig
+	features           config.FeaturesConfig
 }
 
-func NewAPI(repo *repository.Repository) *API {
+func NewAPI(repo service.Store, serverCfg config.ServerConfig, authCfg config.AuthConfig, features config.FeaturesConfig, jwtVerifier *auth.Verifier, heartbeats service.HeartbeatSource) *API {
+	idempotencyTTL := features.IdempotencyTTL
+	if idempotencyTTL <= 0 {
+		idempotencyTTL = service.DefaultIdempotencyTTL
+	}
+
+	// A pending key outlives the request deadline only by the time it takes
+	// to store the response.
+	idempotencyLease := service.DefaultIdempotencyLease
+	if serverCfg.RequestTimeout > 0 {
+		idempotencyLease = serverCfg.RequestTimeout + idempotencyLeaseGrace
+	}
+
 	return &API{
-		teamHandler:       NewTeamHandler(repo),
-		userHandler:       NewUserHandler(repo),
-		prHandler:         NewPRHandler(repo),
-		statisticsHandler: NewStatisticsHandler(repo),
+		healthHandler:      NewHealthHandler(repo, heartbeats),
+		teamHandler:        NewTeamHandler(repo),
+		userHandler:        NewUserHandler(repo),
+		prHandler:          NewPRHandler(repo),
+		statisticsHandler:  NewStatisticsHandler(repo),
+		apiKeyHandler:      NewAPIKeyHandler(repo, authCfg.BootstrapKey),
+		idempotencyService: service.NewIdempotencyService(repo, idempotencyTTL, idempotencyLease),
+		requestTimeout:     serverCfg.RequestTimeout,
+		authEnabled:        authCfg.Enabled,
+		jwtVerifier:        jwtVerifier,
+		jwtCfg:             authCfg.JWT,
+		features:           features,
 	}
 }
 
 func (a *API) RegisterHandlers(r *mux.Router) {
+	r.Use(a.logRequests)
+	if !a.features.DisableMetrics {
+		r.Use(metrics.Middleware)
+	}
+	r.Use(a.timeout)
+	r.Use(a.authenticate)
+	r.Use(a.idempotency)
+
 	a.registerHealthHandlers(r)
 	a.registerTeamHandlers(r)
 	a.registerUserHandlers(r)
 	a.registerPRHandlers(r)
 	a.registerStatisticsHandlers(r)
+	a.registerExportHandlers(r)
+	a.registerAPIKeyHandlers(r)
+	a.registerMetricsHandlers(r)
+	a.registerDocsHandlers(r)
 }
 
 func (a *API) registerHealthHandlers(r *mux.Router) {
-	r.HandleFunc("/health", Health).Methods("GET")
+	r.HandleFunc("/health", a.healthHandler.Live).Methods("GET")
+	r.HandleFunc("/health/live", a.healthHandler.Live).Methods("GET")
+	r.HandleFunc("/health/ready", a.healthHandler.Ready).Methods("GET")
 }
 
 func (a *API) registerTeamHandlers(r *mux.Router) {
-	r.HandleFunc("/team/add", a.teamHandler.AddTeam).Methods("POST")
-	r.HandleFunc("/team/get", a.teamHandler.GetTeam).Methods("GET")
+	r.HandleFunc("/team/add", a.require(models.RoleAdmin, a.teamHandler.AddTeam)).Methods("POST")
+	r.HandleFunc("/team/get", a.require(models.RoleReadOnly, a.teamHandler.GetTeam)).Methods("GET")
 }
 
 func (a *API) registerUserHandlers(r *mux.Router) {
-	r.HandleFunc("/users/setIsActive", a.userHandler.SetIsActive).Methods("POST")
-	r.HandleFunc("/users/getReview", a.userHandler.GetReview).Methods("GET")
+	r.HandleFunc("/users/setIsActive", a.require(models.RoleAdmin, a.userHandler.SetIsActive)).Methods("POST")
+	r.HandleFunc("/users/getReview", a.require(models.RoleReadOnly, a.userHandler.GetReview)).Methods("GET")
+	r.HandleFunc("/users/dashboard", a.require(models.RoleReadOnly, a.userHandler.GetDashboard)).Methods("GET")
 }
 
 func (a *API) registerPRHandlers(r *mux.Router) {
-	r.HandleFunc("/pullRequest/create", a.prHandler.CreatePR).Methods("POST")
-	r.HandleFunc("/pullRequest/merge", a.prHandler.MergePR).Methods("POST")
-	r.HandleFunc("/pullRequest/reassign", a.prHandler.ReassignPR).Methods("POST")
+	r.HandleFunc("/pullRequest/create", a.require(models.RoleIntegration, a.prHandler.CreatePR)).Methods("POST")
+	r.HandleFunc("/pullRequest/merge", a.require(models.RoleIntegration, a.prHandler.MergePR)).Methods("POST")
+	r.HandleFunc("/pullRequest/reassign", a.require(models.RoleIntegration, a.prHandler.ReassignPR)).Methods("POST")
+	r.HandleFunc("/pullRequest/review", a.require(models.RoleIntegration, a.prHandler.ReviewPR)).Methods("POST")
+	r.HandleFunc("/pullRequest/list", a.require(models.RoleReadOnly, a.prHandler.ListPRs)).Methods("GET")
+	r.HandleFunc("/pullRequest/get", a.require(models.RoleReadOnly, a.prHandler.GetPR)).Methods("GET")
 }
 
 func (a *API) registerStatisticsHandlers(r *mux.Router) {
-	r.HandleFunc("/statistics", a.statisticsHandler.GetStatistics).Methods("GET")
+	r.HandleFunc("/statistics", a.require(models.RoleReadOnly, a.statisticsHandler.GetStatistics)).Methods("GET")
+	r.HandleFunc("/statistics/latency", a.require(models.RoleReadOnly, a.statisticsHandler.GetLatency)).Methods("GET")
+	r.HandleFunc("/statistics/fairness", a.require(models.RoleReadOnly, a.statisticsHandler.GetFairness)).Methods("GET")
+	r.HandleFunc("/statistics/graph", a.require(models.RoleReadOnly, a.statisticsHandler.GetGraph)).Methods("GET")
+}
+
+func (a *API) registerExportHandlers(r *mux.Router) {
+	if a.features.DisableExport {
+		return
+	}
+	r.HandleFunc("/export/prs", a.require(models.RoleReadOnly, a.prHandler.ExportPRs)).Methods("GET").Name(routeExportPRs)
+}
+
+func (a *API) registerAPIKeyHandlers(r *mux.Router) {
+	r.HandleFunc("/apiKeys/create", a.require(models.RoleAdmin, a.apiKeyHandler.CreateKey)).Methods("POST")
+	r.HandleFunc("/apiKeys/list", a.require(models.RoleAdmin, a.apiKeyHandler.ListKeys)).Methods("GET")
+	r.HandleFunc("/apiKeys/revoke", a.require(models.RoleAdmin, a.apiKeyHandler.RevokeKey)).Methods("POST")
+}
+
+func (a *API) registerMetricsHandlers(r *mux.Router) {
+	if a.features.DisableMetrics {
+		return
+	}
+	r.Handle("/metrics", promhttp.Handler()).Methods("GET")
 }
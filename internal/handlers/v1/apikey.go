@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+)
+
+type APIKeyHandler struct {
+	service *service.APIKeyService
+}
+
+func NewAPIKeyHandler(repo service.Store, bootstrapKey string) *APIKeyHandler {
+	return &APIKeyHandler{service: service.NewAPIKeyService(repo, bootstrapKey)}
+}
+
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAPIKeyRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+
+	key, rawKey, err := h.service.CreateKey(r.Context(), req)
+	if err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+
+	// The response carries the only copy of the key; keep it out of caches
+	// and the idempotency store.
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCreated)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"api_key": key,
+		"key":     rawKey,
+	}); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.ListKeys(r.Context())
+	if err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"api_keys": keys,
+	}); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	var req models.RevokeAPIKeyRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+	if req.KeyID == "" {
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, errorMsgKeyIDRequired)
+		return
+	}
+
+	if err := h.service.RevokeKey(r.Context(), req.KeyID); err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"key_id":  req.KeyID,
+		"revoked": true,
+	}); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
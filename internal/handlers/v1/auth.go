@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/milyrock/PR-Reviewer/internal/auth"
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
+	"github.com/milyrock/PR-Reviewer/internal/logging"
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+)
+
+// authenticate resolves the caller from X-API-Key or an Authorization bearer
+// credential, which is either an API key or, when a JWT verifier is
+// configured, a signed JWT. Requests without credentials pass through without
+// a principal, so public routes keep working; protected routes are guarded by
+// require.
+func (a *API) authenticate(next http.Handler) http.Handler {
+	if !a.authEnabled {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawKey := r.Header.Get("X-API-Key")
+		bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if rawKey == "" && bearer == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var (
+			p   *httpx.Principal
+			err error
+		)
+		if rawKey == "" && a.jwtVerifier != nil && looksLikeJWT(bearer) {
+			p, err = a.principalFromJWT(bearer)
+		} else {
+			if rawKey == "" {
+				rawKey = bearer
+			}
+			p, err = a.principalFromAPIKey(r.Context(), rawKey)
+		}
+
+		if err != nil {
+			if errors.Is(err, service.ErrUnauthorized) || errors.Is(err, auth.ErrInvalidToken) {
+				httpx.WriteError(w, statusUnauthorized, httpx.ErrorCodeUnauthorized, httpx.ErrorMsgUnauthorized)
+				return
+			}
+			httpx.HandleServiceError(w, r, err)
+			return
+		}
+
+		logging.AddAttrs(r.Context(), slog.String("caller", p.ID), slog.String("role", p.Role))
+
+		next.ServeHTTP(w, r.WithContext(httpx.WithPrincipal(r.Context(), p)))
+	})
+}
+
+func (a *API) principalFromAPIKey(ctx context.Context, rawKey string) (*httpx.Principal, error) {
+	key, err := a.apiKeyHandler.service.Authenticate(ctx, rawKey)
+	if err != nil {
+		return nil, err
+	}
+	return &httpx.Principal{ID: key.KeyID, Role: key.Role}, nil
+}
+
+func (a *API) principalFromJWT(token string) (*httpx.Principal, error) {
+	claims, err := a.jwtVerifier.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	role := a.jwtCfg.DefaultRole
+	if role == "" {
+		role = models.RoleIntegration
+	}
+
+	adminRole := a.jwtCfg.AdminRole
+	if adminRole == "" {
+		adminRole = models.RoleAdmin
+	}
+	for _, claimed := range claims.Roles {
+		if claimed == adminRole {
+			role = models.RoleAdmin
+			break
+		}
+	}
+
+	return &httpx.Principal{ID: claims.UserID, Role: role, UserID: claims.UserID}, nil
+}
+
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// require guards h with the minimum caller role.
+func (a *API) require(role string, h http.HandlerFunc) http.HandlerFunc {
+	return httpx.Authorizer{Enabled: a.authEnabled}.Require(role, h)
+}
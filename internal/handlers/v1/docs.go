@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"embed"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// OpenAPISpec is the OpenAPI 3 description of the routes registered by
+// RegisterHandlers. openapi_test.go checks it against the router and the
+// actual responses, so a handler change that is not reflected here fails the
+// tests.
+//
+//go:embed docs/openapi.json
+var OpenAPISpec []byte
+
+//go:embed docs/index.html
+var swaggerUIPage []byte
+
+// swaggerUIAssets holds the swagger-ui-dist 5.18.2 bundle and stylesheet that
+// docs/index.html loads from /docs/assets/.
+//
+//go:embed docs/swagger-ui
+var swaggerUIAssets embed.FS
+
+func (a *API) registerDocsHandlers(r *mux.Router) {
+	r.HandleFunc("/openapi.json", serveOpenAPISpec).Methods("GET")
+	r.HandleFunc("/docs", serveSwaggerUI).Methods("GET")
+	r.HandleFunc("/docs/assets/{file}", serveSwaggerUIAsset).Methods("GET")
+}
+
+func serveOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(OpenAPISpec); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write openapi spec", "error", err)
+	}
+}
+
+// serveSwaggerUI serves a Swagger UI page for /openapi.json. The UI assets
+// are embedded in the binary, so the page works without internet access.
+func serveSwaggerUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := w.Write(swaggerUIPage); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write swagger ui page", "error", err)
+	}
+}
+
+// serveSwaggerUIAsset serves a file from the embedded Swagger UI assets.
+// {file} matches a single path segment, so only the files of that directory
+// are reachable.
+func serveSwaggerUIAsset(w http.ResponseWriter, r *http.Request) {
+	http.ServeFileFS(w, r, swaggerUIAssets, "docs/swagger-ui/"+mux.Vars(r)["file"])
+}
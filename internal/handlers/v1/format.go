@@ -0,0 +1,48 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	formatJSON   = "json"
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+	formatDOT    = "dot"
+)
+
+const (
+	contentTypeJSON   = "application/json"
+	contentTypeCSV    = "text/csv"
+	contentTypeNDJSON = "application/x-ndjson"
+	contentTypeDOT    = "text/vnd.graphviz"
+)
+
+const exportFlushEvery = 100
+
+// negotiateFormat picks the first media type in Accept that we can produce and
+// falls back to JSON.
+func negotiateFormat(r *http.Request) string {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		switch mediaType {
+		case contentTypeCSV:
+			return formatCSV
+		case contentTypeNDJSON:
+			return formatNDJSON
+		case contentTypeDOT:
+			return formatDOT
+		case contentTypeJSON:
+			return formatJSON
+		}
+	}
+
+	return formatJSON
+}
+
+// flush sends buffered output to the client. The response controller follows
+// Unwrap through the middleware recorders down to the connection.
+func flush(w http.ResponseWriter) {
+	_ = http.NewResponseController(w).Flush()
+}
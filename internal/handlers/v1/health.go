@@ -2,15 +2,44 @@ package v1
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/service"
 )
 
-func Health(w http.ResponseWriter, r *http.Request) {
+type HealthHandler struct {
+	service *service.HealthService
+}
+
+func NewHealthHandler(repo service.Store, heartbeats service.HeartbeatSource) *HealthHandler {
+	return &HealthHandler{service: service.NewHealthService(repo, heartbeats)}
+}
+
+// Live reports that the process is serving requests. It checks no
+// dependencies, so a database outage does not get the process restarted.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(map[string]string{
-		"status": "ok",
+		"status": models.HealthStatusOK,
 	}); err != nil {
-		log.Printf("failed to encode response: %v", err)
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+// Ready runs the dependency checks and answers 503 when any of them fails.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := h.service.Ready(r.Context())
+
+	status := http.StatusOK
+	if report.Status != models.HealthStatusOK {
+		status = statusUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
 	}
 }
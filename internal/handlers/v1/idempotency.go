@@ -0,0 +1,183 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+)
+
+const (
+	idempotencyHeader       = "Idempotency-Key"
+	idempotencyReplayHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+	maxIdempotentBodySize   = 1 << 20
+	idempotencyLeaseGrace   = 5 * time.Second
+)
+
+// replayedHeaders are the response headers stored with an idempotent response.
+// Per-request headers such as X-Request-ID are left out.
+var replayedHeaders = []string{
+	"Content-Type",
+	"Content-Disposition",
+	"Cache-Control",
+	"Location",
+	"ETag",
+}
+
+// idempotency replays the stored response for POST requests that repeat an
+// Idempotency-Key with the same body. Keys are scoped per caller. Server errors
+// and 401/403 responses are not stored, so the client can retry them once the
+// fault or its credentials are fixed. Responses marked
+// Cache-Control: no-store, such as a newly created API key, are recorded
+// without their body and a repeat is refused instead of replayed.
+func (a *API) idempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, errorMsgIdempotencyKeyTooLong)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBodySize+1))
+		if err != nil || len(body) > maxIdempotentBodySize {
+			httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, httpx.ErrorMsgInvalidRequestBody)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := ""
+		if p := httpx.PrincipalFromContext(r.Context()); p != nil {
+			scope = p.ID
+		}
+		scopedKey := scopeIdempotencyKey(scope, key)
+
+		hash := sha256.New()
+		hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		record, err := a.idempotencyService.Begin(r.Context(), scopedKey, requestHash)
+		if err != nil {
+			httpx.HandleServiceError(w, r, err)
+			return
+		}
+
+		if record != nil {
+			header := decodeHeaders(r.Context(), record.ResponseHeaders)
+			if noStore(header) {
+				httpx.HandleServiceError(w, r, service.ErrIdempotencyNotReplayable)
+				return
+			}
+
+			for name, values := range header {
+				w.Header()[name] = values
+			}
+			w.Header().Set(idempotencyReplayHeader, "true")
+			w.WriteHeader(*record.StatusCode)
+			if _, err := w.Write(record.ResponseBody); err != nil {
+				slog.ErrorContext(r.Context(), "failed to write replayed response", "error", err)
+			}
+			return
+		}
+
+		rec := &bodyRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// The outcome must be recorded even if the client has gone away or the
+		// request deadline has passed, otherwise the key stays pending.
+		ctx := context.WithoutCancel(r.Context())
+
+		if !storable(rec.status) {
+			if err := a.idempotencyService.Release(ctx, scopedKey); err != nil {
+				slog.ErrorContext(r.Context(), "failed to release idempotency key", "error", err)
+			}
+			return
+		}
+
+		body = rec.body.Bytes()
+		if noStore(w.Header()) {
+			body = nil
+		}
+
+		if err := a.idempotencyService.Complete(ctx, scopedKey, rec.status, saveHeaders(w.Header()), body); err != nil {
+			slog.ErrorContext(r.Context(), "failed to store idempotent response", "error", err)
+		}
+	})
+}
+
+// scopeIdempotencyKey binds key to the caller. The result is hashed so that it
+// has a fixed length however long the caller ID (e.g. a JWT subject) is.
+func scopeIdempotencyKey(scope, key string) string {
+	sum := sha256.Sum256([]byte(scope + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+func saveHeaders(header http.Header) []byte {
+	saved := make(http.Header, len(replayedHeaders))
+	for _, name := range replayedHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			saved[name] = values
+		}
+	}
+
+	data, _ := json.Marshal(saved)
+	return data
+}
+
+func decodeHeaders(ctx context.Context, data []byte) http.Header {
+	var saved http.Header
+	if len(data) == 0 {
+		return saved
+	}
+
+	if err := json.Unmarshal(data, &saved); err != nil {
+		slog.ErrorContext(ctx, "failed to decode stored response headers", "error", err)
+	}
+	return saved
+}
+
+// storable reports whether a response with status may be replayed. Rejected
+// credentials are left out because authorization runs inside this middleware.
+func storable(status int) bool {
+	return status < http.StatusInternalServerError &&
+		status != http.StatusUnauthorized && status != http.StatusForbidden
+}
+
+func noStore(header http.Header) bool {
+	return strings.Contains(strings.ToLower(header.Get("Cache-Control")), "no-store")
+}
+
+type bodyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *bodyRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *bodyRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
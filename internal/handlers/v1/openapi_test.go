@@ -0,0 +1,348 @@
+package v1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gorilla/mux"
+	"github.com/milyrock/PR-Reviewer/internal/config"
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
+	v1 "github.com/milyrock/PR-Reviewer/internal/handlers/v1"
+	v2 "github.com/milyrock/PR-Reviewer/internal/handlers/v2"
+	"github.com/milyrock/PR-Reviewer/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAdminKey = "openapi-test-admin-key"
+
+func init() {
+	// Non-JSON bodies are checked only for being present; their schema is a
+	// plain string.
+	for _, contentType := range []string{"application/x-ndjson", "text/vnd.graphviz", "text/html", "text/css", "text/javascript"} {
+		openapi3filter.RegisterBodyDecoder(contentType, openapi3filter.PlainBodyDecoder)
+	}
+}
+
+func loadSpec(t *testing.T) *openapi3.T {
+	t.Helper()
+
+	doc, err := openapi3.NewLoader().LoadFromData(v1.OpenAPISpec)
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+	return doc
+}
+
+// newTestRouter mounts both API versions on one router, as cmd/main.go does.
+func newTestRouter() *mux.Router {
+	store := memory.New()
+	api := v1.NewAPI(
+		store,
+		config.ServerConfig{},
+		config.AuthConfig{Enabled: true, BootstrapKey: testAdminKey},
+		config.FeaturesConfig{},
+		nil,
+		nil,
+	)
+
+	r := mux.NewRouter()
+	api.RegisterHandlers(r)
+	v2.NewAPI(store, httpx.Authorizer{Enabled: true}).RegisterHandlers(r)
+	return r
+}
+
+func TestOpenAPISpecDescribesEveryRoute(t *testing.T) {
+	doc := loadSpec(t)
+
+	var registered []string
+	err := newTestRouter().Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if route.GetHandler() == nil {
+			// The /v2 prefix only holds the v2 subrouter.
+			return nil
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			registered = append(registered, method+" "+path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	var documented []string
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented = append(documented, method+" "+path)
+		}
+	}
+
+	sort.Strings(registered)
+	sort.Strings(documented)
+	assert.Equal(t, registered, documented)
+}
+
+type specCall struct {
+	name    string
+	method  string
+	target  string
+	body    string
+	headers map[string]string
+	// invalid marks requests that deliberately violate the spec to exercise
+	// error responses; only their response is validated.
+	invalid bool
+	status  int
+}
+
+// TestOpenAPISpecMatchesHandlers drives every documented operation through
+// the real handlers and validates both the request and the response against
+// the spec, so a handler change that is not reflected in openapi.json fails
+// here.
+func TestOpenAPISpecMatchesHandlers(t *testing.T) {
+	doc := loadSpec(t)
+	specRouter, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	handler := newTestRouter()
+	exercised := make(map[*openapi3.Operation]bool)
+
+	calls := []specCall{
+		{name: "legacy liveness", method: http.MethodGet, target: "/health", status: http.StatusOK},
+		{name: "liveness", method: http.MethodGet, target: "/health/live", status: http.StatusOK},
+		{name: "readiness", method: http.MethodGet, target: "/health/ready", status: http.StatusOK},
+		{name: "spec", method: http.MethodGet, target: "/openapi.json", status: http.StatusOK},
+		{name: "swagger ui", method: http.MethodGet, target: "/docs", status: http.StatusOK},
+		{name: "swagger ui stylesheet", method: http.MethodGet, target: "/docs/assets/swagger-ui.css", status: http.StatusOK},
+		{name: "swagger ui bundle", method: http.MethodGet, target: "/docs/assets/swagger-ui-bundle.js", status: http.StatusOK},
+		{name: "missing swagger ui asset", method: http.MethodGet, target: "/docs/assets/missing.js", status: http.StatusNotFound},
+		{name: "metrics", method: http.MethodGet, target: "/metrics", status: http.StatusOK},
+
+		{name: "add team", method: http.MethodPost, target: "/team/add", status: http.StatusCreated,
+			body: `{"team_name":"backend","members":[{"user_id":"u1","username":"Alice","is_active":true},{"user_id":"u2","username":"Bob","is_active":true},{"user_id":"u3","username":"Charlie","is_active":true}]}`},
+		{name: "add existing team", method: http.MethodPost, target: "/team/add", status: http.StatusBadRequest,
+			body: `{"team_name":"backend","members":[]}`},
+		{name: "add team without name", method: http.MethodPost, target: "/team/add", status: http.StatusBadRequest,
+			body: `{"members":[]}`, invalid: true},
+		{name: "add solo team", method: http.MethodPost, target: "/team/add", status: http.StatusCreated,
+			body: `{"team_name":"solo","members":[{"user_id":"s1","username":"Sam","is_active":true}]}`},
+		{name: "add empty team", method: http.MethodPost, target: "/team/add", status: http.StatusCreated,
+			body: `{"team_name":"empty","members":[]}`},
+		{name: "get empty team", method: http.MethodGet, target: "/team/get?team_name=empty", status: http.StatusOK},
+		{name: "get team", method: http.MethodGet, target: "/team/get?team_name=backend", status: http.StatusOK},
+		{name: "get missing team", method: http.MethodGet, target: "/team/get?team_name=missing", status: http.StatusNotFound},
+		{name: "get team without credentials", method: http.MethodGet, target: "/team/get?team_name=backend", status: http.StatusUnauthorized,
+			headers: map[string]string{"X-API-Key": ""}},
+
+		{name: "deactivate user", method: http.MethodPost, target: "/users/setIsActive", status: http.StatusOK,
+			body: `{"user_id":"u3","is_active":false}`},
+		{name: "create pr", method: http.MethodPost, target: "/pullRequest/create", status: http.StatusCreated,
+			body:    `{"pull_request_id":"pr-1","pull_request_name":"Feature","author_id":"u1"}`,
+			headers: map[string]string{"Idempotency-Key": "create-pr-1"}},
+		{name: "create pr without reviewers", method: http.MethodPost, target: "/pullRequest/create", status: http.StatusCreated,
+			body: `{"pull_request_id":"pr-solo","pull_request_name":"Solo","author_id":"s1"}`},
+		{name: "reviews of author", method: http.MethodGet, target: "/users/getReview?user_id=s1", status: http.StatusOK},
+		{name: "create existing pr", method: http.MethodPost, target: "/pullRequest/create", status: http.StatusConflict,
+			body: `{"pull_request_id":"pr-1","pull_request_name":"Feature","author_id":"u2"}`},
+		{name: "reassign without candidate", method: http.MethodPost, target: "/pullRequest/reassign", status: http.StatusConflict,
+			body: `{"pull_request_id":"pr-1","old_reviewer_id":"u2"}`},
+		{name: "activate user", method: http.MethodPost, target: "/users/setIsActive", status: http.StatusOK,
+			body: `{"user_id":"u3","is_active":true}`},
+		{name: "reassign", method: http.MethodPost, target: "/pullRequest/reassign", status: http.StatusOK,
+			body: `{"pull_request_id":"pr-1","old_reviewer_id":"u2"}`},
+		{name: "review", method: http.MethodPost, target: "/pullRequest/review", status: http.StatusOK,
+			body: `{"pull_request_id":"pr-1","reviewer_id":"u3","decision":"CHANGES_REQUESTED"}`},
+		{name: "review by non-reviewer", method: http.MethodPost, target: "/pullRequest/review", status: http.StatusConflict,
+			body: `{"pull_request_id":"pr-1","reviewer_id":"u2","decision":"APPROVED"}`},
+		{name: "review with unknown decision", method: http.MethodPost, target: "/pullRequest/review", status: http.StatusBadRequest,
+			body: `{"pull_request_id":"pr-1","reviewer_id":"u3","decision":"LGTM"}`, invalid: true},
+		{name: "review missing pr", method: http.MethodPost, target: "/pullRequest/review", status: http.StatusNotFound,
+			body: `{"pull_request_id":"missing","reviewer_id":"u3","decision":"APPROVED"}`},
+		{name: "get pr", method: http.MethodGet, target: "/pullRequest/get?pull_request_id=pr-1", status: http.StatusOK},
+		{name: "get unchanged pr", method: http.MethodGet, target: "/pullRequest/get?pull_request_id=pr-1", status: http.StatusNotModified,
+			headers: map[string]string{"If-None-Match": "*"}},
+		{name: "get missing pr", method: http.MethodGet, target: "/pullRequest/get?pull_request_id=missing", status: http.StatusNotFound},
+		{name: "list prs", method: http.MethodGet, target: "/pullRequest/list?status=OPEN&order=asc&limit=10", status: http.StatusOK},
+		{name: "list prs with bad limit", method: http.MethodGet, target: "/pullRequest/list?limit=0", status: http.StatusBadRequest, invalid: true},
+		{name: "reviews", method: http.MethodGet, target: "/users/getReview?user_id=u3", status: http.StatusOK},
+		{name: "dashboard", method: http.MethodGet, target: "/users/dashboard?user_id=u1", status: http.StatusOK},
+		{name: "merge pr", method: http.MethodPost, target: "/pullRequest/merge", status: http.StatusOK,
+			body: `{"pull_request_id":"pr-1"}`},
+		{name: "review merged pr", method: http.MethodPost, target: "/pullRequest/review", status: http.StatusConflict,
+			body: `{"pull_request_id":"pr-1","reviewer_id":"u3","decision":"APPROVED"}`},
+		{name: "merge unknown field", method: http.MethodPost, target: "/pullRequest/merge", status: http.StatusBadRequest,
+			body: `{"pull_request_id":"pr-1","force":true}`, invalid: true},
+
+		{name: "statistics", method: http.MethodGet, target: "/statistics?bucket=week", status: http.StatusOK},
+		{name: "statistics of unknown team", method: http.MethodGet, target: "/statistics?team_name=missing", status: http.StatusOK},
+		{name: "fairness of unknown team", method: http.MethodGet, target: "/statistics/fairness?team_name=missing", status: http.StatusOK},
+		{name: "graph of unknown team", method: http.MethodGet, target: "/statistics/graph?team_name=missing", status: http.StatusOK},
+		{name: "statistics csv", method: http.MethodGet, target: "/statistics", status: http.StatusOK,
+			headers: map[string]string{"Accept": "text/csv"}},
+		{name: "statistics ndjson", method: http.MethodGet, target: "/statistics?team_name=backend", status: http.StatusOK,
+			headers: map[string]string{"Accept": "application/x-ndjson"}},
+		{name: "latency", method: http.MethodGet, target: "/statistics/latency", status: http.StatusOK},
+		{name: "latency csv", method: http.MethodGet, target: "/statistics/latency", status: http.StatusOK,
+			headers: map[string]string{"Accept": "text/csv"}},
+		{name: "fairness", method: http.MethodGet, target: "/statistics/fairness?from=2020-01-01T00:00:00Z", status: http.StatusOK},
+		{name: "graph", method: http.MethodGet, target: "/statistics/graph", status: http.StatusOK},
+		{name: "graph dot", method: http.MethodGet, target: "/statistics/graph", status: http.StatusOK,
+			headers: map[string]string{"Accept": "text/vnd.graphviz"}},
+		{name: "export", method: http.MethodGet, target: "/export/prs", status: http.StatusOK},
+		{name: "export csv", method: http.MethodGet, target: "/export/prs", status: http.StatusOK,
+			headers: map[string]string{"Accept": "text/csv"}},
+
+		{name: "create api key", method: http.MethodPost, target: "/apiKeys/create", status: http.StatusCreated,
+			body: `{"name":"ci","role":"integration"}`},
+		{name: "list api keys", method: http.MethodGet, target: "/apiKeys/list", status: http.StatusOK},
+		{name: "revoke missing api key", method: http.MethodPost, target: "/apiKeys/revoke", status: http.StatusNotFound,
+			body: `{"key_id":"missing"}`},
+
+		{name: "v2 create team", method: http.MethodPost, target: "/v2/teams", status: http.StatusCreated,
+			body: `{"team_name":"platform","members":[{"user_id":"p1","username":"Pat","is_active":true},{"user_id":"p2","username":"Kim","is_active":true},{"user_id":"p3","username":"Lee","is_active":true}]}`},
+		{name: "v2 create existing team", method: http.MethodPost, target: "/v2/teams", status: http.StatusConflict,
+			body: `{"team_name":"platform","members":[]}`},
+		{name: "v2 get team", method: http.MethodGet, target: "/v2/teams/platform", status: http.StatusOK},
+		{name: "v2 get missing team", method: http.MethodGet, target: "/v2/teams/missing", status: http.StatusNotFound},
+		{name: "v2 deactivate user", method: http.MethodPatch, target: "/v2/users/p3", status: http.StatusOK,
+			body: `{"is_active":false}`},
+		{name: "v2 update user without is_active", method: http.MethodPatch, target: "/v2/users/p3", status: http.StatusBadRequest,
+			body: `{}`, invalid: true},
+		{name: "v2 create pr", method: http.MethodPost, target: "/v2/pull-requests", status: http.StatusCreated,
+			body: `{"pull_request_id":"v2-pr","pull_request_name":"Platform","author_id":"p1"}`},
+		{name: "v2 create existing pr", method: http.MethodPost, target: "/v2/pull-requests", status: http.StatusConflict,
+			body: `{"pull_request_id":"v2-pr","pull_request_name":"Platform","author_id":"p1"}`},
+		{name: "v2 list prs", method: http.MethodGet, target: "/v2/pull-requests?team_name=platform&limit=10", status: http.StatusOK},
+		{name: "v2 get pr", method: http.MethodGet, target: "/v2/pull-requests/v2-pr", status: http.StatusOK},
+		{name: "v2 get missing pr", method: http.MethodGet, target: "/v2/pull-requests/missing", status: http.StatusNotFound},
+		{name: "v2 reassign without candidate", method: http.MethodPost, target: "/v2/pull-requests/v2-pr/reviewers/p2:reassign", status: http.StatusConflict},
+		{name: "v2 review", method: http.MethodPost, target: "/v2/pull-requests/v2-pr/reviewers/p2:review", status: http.StatusOK,
+			body: `{"decision":"APPROVED"}`},
+		{name: "v2 review with unknown decision", method: http.MethodPost, target: "/v2/pull-requests/v2-pr/reviewers/p2:review", status: http.StatusBadRequest,
+			body: `{"decision":"LGTM"}`, invalid: true},
+		{name: "v2 activate user", method: http.MethodPatch, target: "/v2/users/p3", status: http.StatusOK,
+			body: `{"is_active":true}`},
+		{name: "v2 reassign", method: http.MethodPost, target: "/v2/pull-requests/v2-pr/reviewers/p2:reassign", status: http.StatusOK},
+		{name: "v2 reviews", method: http.MethodGet, target: "/v2/users/p3/reviews", status: http.StatusOK},
+		{name: "v2 dashboard", method: http.MethodGet, target: "/v2/users/p1/dashboard", status: http.StatusOK},
+		{name: "v2 merge pr", method: http.MethodPost, target: "/v2/pull-requests/v2-pr/merge", status: http.StatusOK},
+		{name: "v2 merge missing pr", method: http.MethodPost, target: "/v2/pull-requests/missing/merge", status: http.StatusNotFound},
+		{name: "v2 create api key", method: http.MethodPost, target: "/v2/api-keys", status: http.StatusCreated,
+			body: `{"name":"bot","role":"read_only"}`},
+		{name: "v2 list api keys", method: http.MethodGet, target: "/v2/api-keys", status: http.StatusOK},
+		{name: "v2 revoke missing api key", method: http.MethodDelete, target: "/v2/api-keys/missing", status: http.StatusNotFound},
+	}
+
+	for _, call := range calls {
+		route := callSpec(t, specRouter, handler, call)
+		if route != nil {
+			exercised[route.Operation] = true
+		}
+	}
+
+	var createdKey struct {
+		APIKey struct {
+			KeyID string `json:"key_id"`
+		} `json:"api_key"`
+	}
+	rec := serve(handler, specCall{method: http.MethodPost, target: "/apiKeys/create", body: `{"role":"read_only"}`})
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &createdKey))
+	callSpec(t, specRouter, handler, specCall{
+		name: "revoke api key", method: http.MethodPost, target: "/apiKeys/revoke", status: http.StatusOK,
+		body: `{"key_id":"` + createdKey.APIKey.KeyID + `"}`,
+	})
+
+	rec = serve(handler, specCall{method: http.MethodPost, target: "/v2/api-keys", body: `{"role":"read_only"}`})
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &createdKey))
+	callSpec(t, specRouter, handler, specCall{
+		name: "v2 revoke api key", method: http.MethodDelete, target: "/v2/api-keys/" + createdKey.APIKey.KeyID, status: http.StatusNoContent,
+	})
+
+	for path, item := range doc.Paths.Map() {
+		for method, op := range item.Operations() {
+			assert.True(t, exercised[op], "%s %s is not exercised", method, path)
+		}
+	}
+}
+
+func callSpec(t *testing.T, specRouter routers.Router, handler http.Handler, call specCall) *routers.Route {
+	t.Helper()
+
+	req := newCallRequest(call)
+	route, pathParams, err := specRouter.FindRoute(req)
+	if !assert.NoError(t, err, call.name) {
+		return nil
+	}
+
+	input := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			MultiError:         true,
+		},
+	}
+	if !call.invalid {
+		assert.NoError(t, openapi3filter.ValidateRequest(context.Background(), input), call.name)
+	}
+
+	rec := serve(handler, call)
+	assert.Equal(t, call.status, rec.Code, "%s: %s", call.name, rec.Body.String())
+
+	err = openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 rec.Code,
+		Header:                 rec.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+			MultiError:            true,
+		},
+	})
+	assert.NoError(t, err, "%s: %s", call.name, rec.Body.String())
+
+	return route
+}
+
+func serve(handler http.Handler, call specCall) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newCallRequest(call))
+	return rec
+}
+
+func newCallRequest(call specCall) *http.Request {
+	var body io.Reader
+	if call.body != "" {
+		body = strings.NewReader(call.body)
+	}
+
+	req := httptest.NewRequest(call.method, call.target, body)
+	if call.body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-API-Key", testAdminKey)
+	for name, value := range call.headers {
+		if value == "" {
+			req.Header.Del(name)
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	return req
+}
@@ -1,12 +1,17 @@
 package v1
 
 import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
 	"github.com/milyrock/PR-Reviewer/internal/models"
-	"github.com/milyrock/PR-Reviewer/internal/repository"
 	"github.com/milyrock/PR-Reviewer/internal/service"
 )
 
@@ -14,20 +19,20 @@ type PRHandler struct {
 	service *service.PRService
 }
 
-func NewPRHandler(repo *repository.Repository) *PRHandler {
+func NewPRHandler(repo service.Store) *PRHandler {
 	return &PRHandler{service: service.NewPRService(repo)}
 }
 
 func (h *PRHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 	var req models.CreatePRRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, statusBadRequest, errorCodeInvalidRequest, errorMsgInvalidRequestBody)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.HandleServiceError(w, r, err)
 		return
 	}
 
-	createdPR, err := h.service.CreatePR(req)
+	createdPR, err := h.service.CreatePR(r.Context(), req)
 	if err != nil {
-		handleServiceError(w, err)
+		httpx.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -36,20 +41,55 @@ func (h *PRHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewEncoder(w).Encode(map[string]interface{}{
 		"pr": createdPR,
 	}); err != nil {
-		log.Printf("failed to encode response: %v", err)
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+func (h *PRHandler) GetPR(w http.ResponseWriter, r *http.Request) {
+	pullRequestID := r.URL.Query().Get("pull_request_id")
+	if pullRequestID == "" {
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, errorMsgPRIDRequired)
+		return
+	}
+
+	pr, err := h.service.GetPR(r.Context(), pullRequestID)
+	if err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"pr": pr,
+	})
+	if err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+	body = append(body, '\n')
+
+	etag := bodyETag(body)
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(statusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(body); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write response", "error", err)
 	}
 }
 
 func (h *PRHandler) MergePR(w http.ResponseWriter, r *http.Request) {
 	var req models.MergePRRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, statusBadRequest, errorCodeInvalidRequest, errorMsgInvalidRequestBody)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.HandleServiceError(w, r, err)
 		return
 	}
 
-	pr, err := h.service.MergePR(req)
+	pr, err := h.service.MergePR(r.Context(), req)
 	if err != nil {
-		handleServiceError(w, err)
+		httpx.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -57,20 +97,25 @@ func (h *PRHandler) MergePR(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewEncoder(w).Encode(map[string]interface{}{
 		"pr": pr,
 	}); err != nil {
-		log.Printf("failed to encode response: %v", err)
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
 	}
 }
 
 func (h *PRHandler) ReassignPR(w http.ResponseWriter, r *http.Request) {
 	var req models.ReassignPRRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, statusBadRequest, errorCodeInvalidRequest, errorMsgInvalidRequestBody)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+
+	if !httpx.CanActAsUser(r, req.OldUserID) {
+		httpx.WriteError(w, statusForbidden, httpx.ErrorCodeForbidden, httpx.ErrorMsgReassignSelfOnly)
 		return
 	}
 
-	updatedPR, newReviewerID, err := h.service.ReassignPR(req)
+	updatedPR, newReviewerID, err := h.service.ReassignPR(r.Context(), req)
 	if err != nil {
-		handleServiceError(w, err)
+		httpx.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -79,6 +124,157 @@ func (h *PRHandler) ReassignPR(w http.ResponseWriter, r *http.Request) {
 		"pr":          updatedPR,
 		"replaced_by": newReviewerID,
 	}); err != nil {
-		log.Printf("failed to encode response: %v", err)
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+func (h *PRHandler) ReviewPR(w http.ResponseWriter, r *http.Request) {
+	var req models.ReviewPRRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
 	}
+
+	if !httpx.CanActAsUser(r, req.ReviewerID) {
+		httpx.WriteError(w, statusForbidden, httpx.ErrorCodeForbidden, httpx.ErrorMsgReviewSelfOnly)
+		return
+	}
+
+	pr, err := h.service.ReviewPR(r.Context(), req)
+	if err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"pr": pr,
+	}); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+func (h *PRHandler) ListPRs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := httpx.ParsePRListFilter(query)
+	if err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+
+	resp, err := h.service.ListPRs(r.Context(), filter, query.Get("cursor"))
+	if err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+func (h *PRHandler) ExportPRs(w http.ResponseWriter, r *http.Request) {
+	if negotiateFormat(r) == formatCSV {
+		h.exportPRsCSV(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeNDJSON)
+
+	enc := json.NewEncoder(w)
+	written := 0
+	err := h.service.ExportPRs(r.Context(), func(pr *models.PullRequest) error {
+		if err := enc.Encode(pr); err != nil {
+			return err
+		}
+
+		written++
+		if written%exportFlushEvery == 0 {
+			flush(w)
+		}
+
+		return nil
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to export pull requests", "error", err)
+	}
+}
+
+func (h *PRHandler) exportPRsCSV(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentTypeCSV)
+	w.Header().Set("Content-Disposition", `attachment; filename="pull_requests.csv"`)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{
+		"pull_request_id", "pull_request_name", "author_id", "status",
+		"created_at", "merged_at", "reviewers", "reviewers_assigned_at",
+	})
+
+	written := 0
+	err := h.service.ExportPRs(r.Context(), func(pr *models.PullRequest) error {
+		assignedAt := make([]string, 0, len(pr.Reviewers))
+		for _, reviewer := range pr.Reviewers {
+			assignedAt = append(assignedAt, reviewer.AssignedAt.Format(time.RFC3339))
+		}
+
+		if err := cw.Write([]string{
+			pr.PullRequestID,
+			pr.PullRequestName,
+			pr.AuthorID,
+			pr.Status,
+			formatTime(pr.CreatedAt),
+			formatTime(pr.MergedAt),
+			strings.Join(pr.AssignedReviewers, ";"),
+			strings.Join(assignedAt, ";"),
+		}); err != nil {
+			return err
+		}
+
+		written++
+		if written%exportFlushEvery == 0 {
+			cw.Flush()
+			flush(w)
+		}
+
+		return cw.Error()
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to export pull requests", "error", err)
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// bodyETag is a strong ETag for a serialised response. Hashing the body
+// rather than using updated_at also catches changes that do not touch the PR
+// row, such as a reviewer being renamed, deactivated or submitting a decision.
+func bodyETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
 }
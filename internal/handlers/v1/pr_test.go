@@ -0,0 +1,47 @@
+package v1_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPRETagFollowsResponse(t *testing.T) {
+	handler := newTestRouter()
+	for _, call := range []specCall{
+		{method: http.MethodPost, target: "/team/add", body: `{"team_name":"backend","members":[{"user_id":"u1","username":"Alice","is_active":true},{"user_id":"u2","username":"Bob","is_active":true}]}`},
+		{method: http.MethodPost, target: "/pullRequest/create", body: `{"pull_request_id":"pr-1","pull_request_name":"Feature","author_id":"u1"}`},
+	} {
+		require.Less(t, serve(handler, call).Code, http.StatusBadRequest, call.target)
+	}
+
+	get := func(ifNoneMatch string) (int, string) {
+		rec := serve(handler, specCall{
+			method:  http.MethodGet,
+			target:  "/pullRequest/get?pull_request_id=pr-1",
+			headers: map[string]string{"If-None-Match": ifNoneMatch},
+		})
+		return rec.Code, rec.Header().Get("ETag")
+	}
+
+	status, etag := get("")
+	require.Equal(t, http.StatusOK, status)
+	require.NotEmpty(t, etag)
+
+	status, _ = get(etag)
+	assert.Equal(t, http.StatusNotModified, status)
+
+	// Deactivating the reviewer leaves the PR row alone but changes the
+	// reviewer in the response.
+	rec := serve(handler, specCall{method: http.MethodPost, target: "/users/setIsActive", body: `{"user_id":"u2","is_active":false}`})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	status, changed := get(etag)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEqual(t, etag, changed)
+
+	status, _ = get(changed)
+	assert.Equal(t, http.StatusNotModified, status)
+}
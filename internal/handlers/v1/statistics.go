@@ -1,11 +1,17 @@
 package v1
 
 import (
+	"bufio"
+	"context"
+	"encoding/csv"
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strconv"
 
-	"github.com/milyrock/PR-Reviewer/internal/repository"
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
+	"github.com/milyrock/PR-Reviewer/internal/models"
 	"github.com/milyrock/PR-Reviewer/internal/service"
 )
 
@@ -13,19 +19,280 @@ type StatisticsHandler struct {
 	service *service.StatisticsService
 }
 
-func NewStatisticsHandler(repo *repository.Repository) *StatisticsHandler {
+func NewStatisticsHandler(repo service.Store) *StatisticsHandler {
 	return &StatisticsHandler{service: service.NewStatisticsService(repo)}
 }
 
 func (h *StatisticsHandler) GetStatistics(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.service.GetStatistics()
+	query := r.URL.Query()
+
+	filter := models.StatisticsFilter{
+		TeamName: query.Get("team_name"),
+		Status:   query.Get("status"),
+		Bucket:   query.Get("bucket"),
+	}
+
+	if filter.Status != "" && filter.Status != "OPEN" && filter.Status != "MERGED" {
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, httpx.ErrorMsgInvalidStatus)
+		return
+	}
+
+	if filter.Bucket != "" && filter.Bucket != "day" && filter.Bucket != "week" {
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, errorMsgInvalidBucket)
+		return
+	}
+
+	var err error
+	if filter.From, err = httpx.ParseTimeParam(query, "from"); err != nil {
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, httpx.ErrorMsgInvalidTime)
+		return
+	}
+	if filter.To, err = httpx.ParseTimeParam(query, "to"); err != nil {
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, httpx.ErrorMsgInvalidTime)
+		return
+	}
+
+	stats, err := h.service.GetStatistics(r.Context(), filter)
 	if err != nil {
-		writeError(w, statusInternalError, errorCodeInternalError, err.Error())
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+
+	switch negotiateFormat(r) {
+	case formatCSV:
+		writeStatisticsCSV(r.Context(), w, stats)
+		return
+	case formatNDJSON:
+		writeStatisticsNDJSON(r.Context(), w, stats)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(stats); err != nil {
-		log.Printf("failed to encode response: %v", err)
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+func (h *StatisticsHandler) GetLatency(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := models.StatisticsFilter{
+		TeamName: query.Get("team_name"),
+	}
+
+	var err error
+	if filter.From, err = httpx.ParseTimeParam(query, "from"); err != nil {
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, httpx.ErrorMsgInvalidTime)
+		return
+	}
+	if filter.To, err = httpx.ParseTimeParam(query, "to"); err != nil {
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, httpx.ErrorMsgInvalidTime)
+		return
+	}
+
+	latency, err := h.service.GetLatency(r.Context(), filter)
+	if err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+
+	switch negotiateFormat(r) {
+	case formatCSV:
+		writeLatencyCSV(r.Context(), w, latency)
+		return
+	case formatNDJSON:
+		writeLatencyNDJSON(r.Context(), w, latency)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(latency); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+func (h *StatisticsHandler) GetFairness(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := models.StatisticsFilter{
+		TeamName: query.Get("team_name"),
+	}
+
+	var err error
+	if filter.From, err = httpx.ParseTimeParam(query, "from"); err != nil {
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, httpx.ErrorMsgInvalidTime)
+		return
+	}
+	if filter.To, err = httpx.ParseTimeParam(query, "to"); err != nil {
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, httpx.ErrorMsgInvalidTime)
+		return
+	}
+
+	fairness, err := h.service.GetFairness(r.Context(), filter)
+	if err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(fairness); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+func (h *StatisticsHandler) GetGraph(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := models.StatisticsFilter{
+		TeamName: query.Get("team_name"),
+	}
+
+	var err error
+	if filter.From, err = httpx.ParseTimeParam(query, "from"); err != nil {
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, httpx.ErrorMsgInvalidTime)
+		return
+	}
+	if filter.To, err = httpx.ParseTimeParam(query, "to"); err != nil {
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, httpx.ErrorMsgInvalidTime)
+		return
+	}
+
+	graph, err := h.service.GetCollaborationGraph(r.Context(), filter)
+	if err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+
+	if negotiateFormat(r) == formatDOT {
+		writeGraphDOT(r.Context(), w, graph)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(graph); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+func writeGraphDOT(ctx context.Context, w http.ResponseWriter, graph *models.CollaborationGraph) {
+	w.Header().Set("Content-Type", contentTypeDOT)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph reviews {")
+	for _, node := range graph.Nodes {
+		fmt.Fprintf(bw, "  %s [label=%s];\n", strconv.Quote(node.UserID), strconv.Quote(node.Username+" ("+node.TeamName+")"))
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(bw, "  %s -> %s [weight=%d, label=\"%d\"];\n", strconv.Quote(edge.AuthorID), strconv.Quote(edge.ReviewerID), edge.Weight, edge.Weight)
+	}
+	fmt.Fprintln(bw, "}")
+
+	if err := bw.Flush(); err != nil {
+		slog.ErrorContext(ctx, "failed to write dot response", "error", err)
+	}
+}
+
+type latencySection struct {
+	metric string
+	stats  []models.LatencyStats
+}
+
+func latencySections(latency *models.LatencyResponse) []latencySection {
+	return []latencySection{
+		{"time_to_merge_by_team", latency.TimeToMergeByTeam},
+		{"time_to_merge_by_author", latency.TimeToMergeByAuthor},
+		{"time_to_merge_by_reviewer", latency.TimeToMergeByReviewer},
+		{"time_to_merge_by_week", latency.TimeToMergeByWeek},
+		{"assignment_to_merge_by_reviewer", latency.AssignmentToMergeByReviewer},
+		{"assignment_to_merge_by_week", latency.AssignmentToMergeByWeek},
+		{"assignment_to_first_decision_by_reviewer", latency.AssignmentToFirstDecisionByReviewer},
+		{"assignment_to_first_decision_by_week", latency.AssignmentToFirstDecisionByWeek},
+	}
+}
+
+func writeLatencyCSV(ctx context.Context, w http.ResponseWriter, latency *models.LatencyResponse) {
+	w.Header().Set("Content-Type", contentTypeCSV)
+	w.Header().Set("Content-Disposition", `attachment; filename="latency.csv"`)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"metric", "key", "count", "p50_seconds", "p90_seconds", "p99_seconds"})
+
+	for _, section := range latencySections(latency) {
+		for _, stat := range section.stats {
+			_ = cw.Write([]string{
+				section.metric,
+				stat.Key,
+				strconv.Itoa(stat.Count),
+				strconv.FormatFloat(stat.P50Seconds, 'f', 3, 64),
+				strconv.FormatFloat(stat.P90Seconds, 'f', 3, 64),
+				strconv.FormatFloat(stat.P99Seconds, 'f', 3, 64),
+			})
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		slog.ErrorContext(ctx, "failed to write csv response", "error", err)
+	}
+}
+
+func writeLatencyNDJSON(ctx context.Context, w http.ResponseWriter, latency *models.LatencyResponse) {
+	w.Header().Set("Content-Type", contentTypeNDJSON)
+
+	enc := json.NewEncoder(w)
+	for _, section := range latencySections(latency) {
+		for _, stat := range section.stats {
+			if err := enc.Encode(struct {
+				Metric string `json:"metric"`
+				models.LatencyStats
+			}{section.metric, stat}); err != nil {
+				slog.ErrorContext(ctx, "failed to encode response", "error", err)
+				return
+			}
+		}
+	}
+}
+
+func writeStatisticsCSV(ctx context.Context, w http.ResponseWriter, stats *models.StatisticsResponse) {
+	w.Header().Set("Content-Type", contentTypeCSV)
+	w.Header().Set("Content-Disposition", `attachment; filename="statistics.csv"`)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"kind", "id", "name", "count"})
+
+	for _, stat := range stats.UserStats {
+		_ = cw.Write([]string{"user", stat.UserID, stat.Username, strconv.Itoa(stat.ReviewCount)})
+	}
+	for _, stat := range stats.PRStats {
+		_ = cw.Write([]string{"pr", stat.PullRequestID, stat.PullRequestName, strconv.Itoa(stat.ReviewerCount)})
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		slog.ErrorContext(ctx, "failed to write csv response", "error", err)
+	}
+}
+
+func writeStatisticsNDJSON(ctx context.Context, w http.ResponseWriter, stats *models.StatisticsResponse) {
+	w.Header().Set("Content-Type", contentTypeNDJSON)
+
+	enc := json.NewEncoder(w)
+	for _, stat := range stats.UserStats {
+		if err := enc.Encode(struct {
+			Kind string `json:"kind"`
+			models.UserReviewStats
+		}{"user", stat}); err != nil {
+			slog.ErrorContext(ctx, "failed to encode response", "error", err)
+			return
+		}
+	}
+	for _, stat := range stats.PRStats {
+		if err := enc.Encode(struct {
+			Kind string `json:"kind"`
+			models.PRReviewStats
+		}{"pr", stat}); err != nil {
+			slog.ErrorContext(ctx, "failed to encode response", "error", err)
+			return
+		}
 	}
 }
@@ -2,11 +2,11 @@ package v1
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
 	"github.com/milyrock/PR-Reviewer/internal/models"
-	"github.com/milyrock/PR-Reviewer/internal/repository"
 	"github.com/milyrock/PR-Reviewer/internal/service"
 )
 
@@ -14,20 +14,20 @@ type TeamHandler struct {
 	service *service.TeamService
 }
 
-func NewTeamHandler(repo *repository.Repository) *TeamHandler {
+func NewTeamHandler(repo service.Store) *TeamHandler {
 	return &TeamHandler{service: service.NewTeamService(repo)}
 }
 
 func (h *TeamHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateTeamRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, statusBadRequest, errorCodeInvalidRequest, errorMsgInvalidRequestBody)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.HandleServiceError(w, r, err)
 		return
 	}
 
-	team, err := h.service.AddTeam(req)
+	team, err := h.service.AddTeam(r.Context(), req)
 	if err != nil {
-		handleServiceError(w, err)
+		httpx.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -36,25 +36,25 @@ func (h *TeamHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewEncoder(w).Encode(map[string]interface{}{
 		"team": team,
 	}); err != nil {
-		log.Printf("failed to encode response: %v", err)
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
 	}
 }
 
 func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 	teamName := r.URL.Query().Get("team_name")
 	if teamName == "" {
-		writeError(w, statusBadRequest, errorCodeInvalidRequest, errorMsgTeamNameRequired)
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, errorMsgTeamNameRequired)
 		return
 	}
 
-	team, err := h.service.GetTeam(teamName)
+	team, err := h.service.GetTeam(r.Context(), teamName)
 	if err != nil {
-		handleServiceError(w, err)
+		httpx.HandleServiceError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(team); err != nil {
-		log.Printf("failed to encode response: %v", err)
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
 	}
 }
@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// streamingRoutes name the routes whose responses are written for as long as
+// the data takes, such as the PR export. They are not bound by the request
+// timeout or by the server's write deadline.
+var streamingRoutes = map[string]bool{
+	routeExportPRs: true,
+}
+
+// timeout bounds every request with the configured deadline. Handlers pass the
+// request context down to the database, so queries still running when it
+// expires are cancelled and reported as TIMEOUT.
+func (a *API) timeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := mux.CurrentRoute(r); route != nil && streamingRoutes[route.GetName()] {
+			err := http.NewResponseController(w).SetWriteDeadline(time.Time{})
+			if err != nil && !errors.Is(err, http.ErrNotSupported) {
+				slog.WarnContext(r.Context(), "failed to clear write deadline", "error", err)
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if a.requestTimeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), a.requestTimeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,48 @@
+package v1
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutExemptsStreamingRoutes(t *testing.T) {
+	a := &API{requestTimeout: 20 * time.Millisecond}
+
+	r := mux.NewRouter()
+	r.Use(a.timeout)
+	r.HandleFunc("/export/prs", func(w http.ResponseWriter, r *http.Request) {
+		_, hasDeadline := r.Context().Deadline()
+		assert.False(t, hasDeadline)
+
+		// Outlive both the request timeout and the server write timeout.
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte("done"))
+	}).Name(routeExportPRs)
+	r.HandleFunc("/team/get", func(w http.ResponseWriter, r *http.Request) {
+		_, hasDeadline := r.Context().Deadline()
+		assert.True(t, hasDeadline)
+	})
+
+	srv := httptest.NewUnstartedServer(r)
+	srv.Config.WriteTimeout = 50 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/export/prs")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "done", string(body))
+
+	resp, err = http.Get(srv.URL + "/team/get")
+	require.NoError(t, err)
+	resp.Body.Close()
+}
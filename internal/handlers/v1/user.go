@@ -2,11 +2,11 @@ package v1
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
 	"github.com/milyrock/PR-Reviewer/internal/models"
-	"github.com/milyrock/PR-Reviewer/internal/repository"
 	"github.com/milyrock/PR-Reviewer/internal/service"
 )
 
@@ -14,20 +14,20 @@ type UserHandler struct {
 	service *service.UserService
 }
 
-func NewUserHandler(repo *repository.Repository) *UserHandler {
+func NewUserHandler(repo service.Store) *UserHandler {
 	return &UserHandler{service: service.NewUserService(repo)}
 }
 
 func (h *UserHandler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 	var req models.SetIsActiveRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, statusBadRequest, errorCodeInvalidRequest, errorMsgInvalidRequestBody)
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		httpx.HandleServiceError(w, r, err)
 		return
 	}
 
-	user, err := h.service.SetIsActive(req)
+	user, err := h.service.SetIsActive(r.Context(), req)
 	if err != nil {
-		handleServiceError(w, err)
+		httpx.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -35,20 +35,23 @@ func (h *UserHandler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewEncoder(w).Encode(map[string]interface{}{
 		"user": user,
 	}); err != nil {
-		log.Printf("failed to encode response: %v", err)
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
 	}
 }
 
 func (h *UserHandler) GetReview(w http.ResponseWriter, r *http.Request) {
 	userID := r.URL.Query().Get("user_id")
 	if userID == "" {
-		writeError(w, statusBadRequest, errorCodeInvalidRequest, errorMsgUserIDRequired)
+		userID = httpx.CallerUserID(r)
+	}
+	if userID == "" {
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, errorMsgUserIDRequired)
 		return
 	}
 
-	prs, err := h.service.GetReview(userID)
+	prs, err := h.service.GetReview(r.Context(), userID)
 	if err != nil {
-		handleServiceError(w, err)
+		httpx.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -57,6 +60,28 @@ func (h *UserHandler) GetReview(w http.ResponseWriter, r *http.Request) {
 		"user_id":       userID,
 		"pull_requests": prs,
 	}); err != nil {
-		log.Printf("failed to encode response: %v", err)
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+func (h *UserHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = httpx.CallerUserID(r)
+	}
+	if userID == "" {
+		httpx.WriteError(w, statusBadRequest, httpx.ErrorCodeInvalidRequest, errorMsgUserIDRequired)
+		return
+	}
+
+	dashboard, err := h.service.GetDashboard(r.Context(), userID)
+	if err != nil {
+		httpx.HandleServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(dashboard); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
 	}
 }
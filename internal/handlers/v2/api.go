@@ -0,0 +1,70 @@
+package v2
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+)
+
+// API is the resource-oriented version of the API. It shares the services
+// and the storage with v1; only routing, status codes and response shapes
+// differ.
+type API struct {
+	auth          httpx.Authorizer
+	teamHandler   *TeamHandler
+	userHandler   *UserHandler
+	prHandler     *PRHandler
+	apiKeyHandler *APIKeyHandler
+}
+
+// NewAPI checks roles with auth against the principal that v1's
+// authentication middleware resolves, so both versions accept the same API
+// keys, JWTs and roles.
+func NewAPI(repo service.Store, auth httpx.Authorizer) *API {
+	return &API{
+		auth:          auth,
+		teamHandler:   NewTeamHandler(repo),
+		userHandler:   NewUserHandler(repo),
+		prHandler:     NewPRHandler(repo),
+		apiKeyHandler: NewAPIKeyHandler(repo),
+	}
+}
+
+// RegisterHandlers mounts the routes under /v2. r must be the router v1
+// registered its middleware on: request IDs, access logs, metrics, timeouts,
+// authentication and idempotency come from there.
+func (a *API) RegisterHandlers(r *mux.Router) {
+	s := r.PathPrefix(pathPrefix).Subrouter()
+
+	a.registerTeamHandlers(s)
+	a.registerUserHandlers(s)
+	a.registerPRHandlers(s)
+	a.registerAPIKeyHandlers(s)
+}
+
+func (a *API) registerTeamHandlers(r *mux.Router) {
+	r.HandleFunc("/teams", a.auth.Require(models.RoleAdmin, a.teamHandler.CreateTeam)).Methods("POST")
+	r.HandleFunc("/teams/{name}", a.auth.Require(models.RoleReadOnly, a.teamHandler.GetTeam)).Methods("GET")
+}
+
+func (a *API) registerUserHandlers(r *mux.Router) {
+	r.HandleFunc("/users/{id}", a.auth.Require(models.RoleAdmin, a.userHandler.UpdateUser)).Methods("PATCH")
+	r.HandleFunc("/users/{id}/reviews", a.auth.Require(models.RoleReadOnly, a.userHandler.GetReviews)).Methods("GET")
+	r.HandleFunc("/users/{id}/dashboard", a.auth.Require(models.RoleReadOnly, a.userHandler.GetDashboard)).Methods("GET")
+}
+
+func (a *API) registerPRHandlers(r *mux.Router) {
+	r.HandleFunc("/pull-requests", a.auth.Require(models.RoleIntegration, a.prHandler.CreatePR)).Methods("POST")
+	r.HandleFunc("/pull-requests", a.auth.Require(models.RoleReadOnly, a.prHandler.ListPRs)).Methods("GET")
+	r.HandleFunc("/pull-requests/{id}", a.auth.Require(models.RoleReadOnly, a.prHandler.GetPR)).Methods("GET")
+	r.HandleFunc("/pull-requests/{id}/merge", a.auth.Require(models.RoleIntegration, a.prHandler.MergePR)).Methods("POST")
+	r.HandleFunc("/pull-requests/{id}/reviewers/{uid}:reassign", a.auth.Require(models.RoleIntegration, a.prHandler.ReassignReviewer)).Methods("POST")
+	r.HandleFunc("/pull-requests/{id}/reviewers/{uid}:review", a.auth.Require(models.RoleIntegration, a.prHandler.ReviewPR)).Methods("POST")
+}
+
+func (a *API) registerAPIKeyHandlers(r *mux.Router) {
+	r.HandleFunc("/api-keys", a.auth.Require(models.RoleAdmin, a.apiKeyHandler.CreateKey)).Methods("POST")
+	r.HandleFunc("/api-keys", a.auth.Require(models.RoleAdmin, a.apiKeyHandler.ListKeys)).Methods("GET")
+	r.HandleFunc("/api-keys/{id}", a.auth.Require(models.RoleAdmin, a.apiKeyHandler.RevokeKey)).Methods("DELETE")
+}
@@ -0,0 +1,345 @@
+package v2_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/milyrock/PR-Reviewer/internal/config"
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
+	v1 "github.com/milyrock/PR-Reviewer/internal/handlers/v1"
+	v2 "github.com/milyrock/PR-Reviewer/internal/handlers/v2"
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const adminKey = "v2-test-admin-key"
+
+type client struct {
+	t      *testing.T
+	router *mux.Router
+	key    string
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+
+	store := memory.New()
+	api := v1.NewAPI(store, config.ServerConfig{}, config.AuthConfig{Enabled: true, BootstrapKey: adminKey}, config.FeaturesConfig{}, nil, nil)
+
+	r := mux.NewRouter()
+	api.RegisterHandlers(r)
+	v2.NewAPI(store, httpx.Authorizer{Enabled: true}).RegisterHandlers(r)
+
+	return &client{t: t, router: r, key: adminKey}
+}
+
+func (c *client) do(method, target, body string) *httptest.ResponseRecorder {
+	c.t.Helper()
+	return c.doWithHeaders(method, target, body, nil)
+}
+
+func (c *client) doWithHeaders(method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.key != "" {
+		req.Header.Set("X-API-Key", c.key)
+	}
+
+	rec := httptest.NewRecorder()
+	c.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, dst interface{}) {
+	t.Helper()
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), dst), rec.Body.String())
+}
+
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp models.ErrorResponse
+	decode(t, rec, &resp)
+	return resp.Error.Code
+}
+
+func createBackendTeam(c *client) {
+	c.t.Helper()
+	rec := c.do(http.MethodPost, "/v2/teams", `{"team_name":"backend","members":[
+		{"user_id":"u1","username":"Alice","is_active":true},
+		{"user_id":"u2","username":"Bob","is_active":true},
+		{"user_id":"u3","username":"Charlie","is_active":false}]}`)
+	require.Equal(c.t, http.StatusCreated, rec.Code, rec.Body.String())
+}
+
+func TestTeams(t *testing.T) {
+	c := newClient(t)
+
+	rec := c.do(http.MethodPost, "/v2/teams", `{"team_name":"backend team","members":[{"user_id":"u1","username":"Alice","is_active":true}]}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "/v2/teams/backend%20team", rec.Header().Get("Location"))
+
+	var team models.Team
+	decode(t, rec, &team)
+	assert.Equal(t, "backend team", team.TeamName)
+	assert.Len(t, team.Members, 1)
+
+	rec = c.do(http.MethodGet, rec.Header().Get("Location"), "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	decode(t, rec, &team)
+	assert.Equal(t, "backend team", team.TeamName)
+
+	rec = c.do(http.MethodPost, "/v2/teams", `{"team_name":"backend team","members":[]}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "TEAM_EXISTS", errorCode(t, rec))
+
+	rec = c.do(http.MethodGet, "/v2/teams/missing", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "TEAM_NOT_FOUND", errorCode(t, rec))
+
+	rec = c.do(http.MethodPost, "/v2/teams", `{"members":[]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "INVALID_REQUEST", errorCode(t, rec))
+}
+
+func TestIdempotentReplayKeepsHeaders(t *testing.T) {
+	c := newClient(t)
+	headers := map[string]string{"Idempotency-Key": "create-backend"}
+	body := `{"team_name":"backend","members":[]}`
+
+	first := c.doWithHeaders(http.MethodPost, "/v2/teams", body, headers)
+	require.Equal(t, http.StatusCreated, first.Code, first.Body.String())
+
+	replay := c.doWithHeaders(http.MethodPost, "/v2/teams", body, headers)
+	require.Equal(t, http.StatusCreated, replay.Code, replay.Body.String())
+	assert.Equal(t, "true", replay.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, "/v2/teams/backend", replay.Header().Get("Location"))
+	assert.Equal(t, "application/json", replay.Header().Get("Content-Type"))
+	assert.JSONEq(t, first.Body.String(), replay.Body.String())
+	assert.NotEqual(t, first.Header().Get("X-Request-ID"), replay.Header().Get("X-Request-ID"))
+}
+
+func TestIdempotencyNeverReplaysAPIKeys(t *testing.T) {
+	c := newClient(t)
+	headers := map[string]string{"Idempotency-Key": "create-dashboards-key"}
+	body := `{"name":"dashboards","role":"read_only"}`
+
+	first := c.doWithHeaders(http.MethodPost, "/v2/api-keys", body, headers)
+	require.Equal(t, http.StatusCreated, first.Code, first.Body.String())
+	assert.Equal(t, "no-store", first.Header().Get("Cache-Control"))
+
+	replay := c.doWithHeaders(http.MethodPost, "/v2/api-keys", body, headers)
+	require.Equal(t, http.StatusConflict, replay.Code, replay.Body.String())
+	assert.Equal(t, "IDEMPOTENCY_NOT_REPLAYABLE", errorCode(t, replay))
+	assert.NotContains(t, replay.Body.String(), "prr_")
+}
+
+func TestIdempotencyDoesNotStoreAuthFailures(t *testing.T) {
+	c := newClient(t)
+
+	rec := c.do(http.MethodPost, "/v2/api-keys", `{"name":"dashboards","role":"read_only"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created struct {
+		Key string `json:"key"`
+	}
+	decode(t, rec, &created)
+
+	body := `{"team_name":"backend","members":[]}`
+	for _, tc := range []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{name: "unauthenticated", key: "", status: http.StatusUnauthorized},
+		{name: "forbidden", key: created.Key, status: http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			caller := &client{t: t, router: c.router, key: tc.key}
+			headers := map[string]string{"Idempotency-Key": "create-backend-" + tc.name}
+
+			for i := 0; i < 2; i++ {
+				rec := caller.doWithHeaders(http.MethodPost, "/v2/teams", body, headers)
+				assert.Equal(t, tc.status, rec.Code, rec.Body.String())
+				assert.Empty(t, rec.Header().Get("Idempotent-Replayed"))
+			}
+		})
+	}
+}
+
+func TestUsers(t *testing.T) {
+	c := newClient(t)
+	createBackendTeam(c)
+
+	rec := c.do(http.MethodPatch, "/v2/users/u3", `{"is_active":true}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var user models.User
+	decode(t, rec, &user)
+	assert.Equal(t, "u3", user.UserID)
+	assert.True(t, user.IsActive)
+
+	rec = c.do(http.MethodPatch, "/v2/users/u3", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var resp models.ErrorResponse
+	decode(t, rec, &resp)
+	assert.Equal(t, []models.FieldError{{Field: "is_active", Message: "is required"}}, resp.Error.Details)
+
+	rec = c.do(http.MethodPatch, "/v2/users/missing", `{"is_active":false}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "USER_NOT_FOUND", errorCode(t, rec))
+
+	rec = c.do(http.MethodGet, "/v2/users/u2/reviews", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"pull_requests":[]}`, rec.Body.String())
+
+	rec = c.do(http.MethodGet, "/v2/users/u2/dashboard", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var dashboard models.UserDashboard
+	decode(t, rec, &dashboard)
+	assert.Equal(t, "u2", dashboard.UserID)
+}
+
+func TestPullRequestLifecycle(t *testing.T) {
+	c := newClient(t)
+	createBackendTeam(c)
+
+	rec := c.do(http.MethodPost, "/v2/pull-requests", `{"pull_request_id":"pr-1","pull_request_name":"Feature","author_id":"u1"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "/v2/pull-requests/pr-1", rec.Header().Get("Location"))
+
+	var pr models.PullRequest
+	decode(t, rec, &pr)
+	assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+
+	rec = c.do(http.MethodPost, "/v2/pull-requests", `{"pull_request_id":"pr-1","pull_request_name":"Feature","author_id":"u1"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "PR_EXISTS", errorCode(t, rec))
+
+	rec = c.do(http.MethodGet, "/v2/pull-requests/pr-1", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	decode(t, rec, &pr)
+	assert.Equal(t, "OPEN", pr.Status)
+
+	rec = c.do(http.MethodGet, "/v2/pull-requests?status=OPEN&author_id=u1", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var list models.PRListResponse
+	decode(t, rec, &list)
+	require.Len(t, list.PullRequests, 1)
+	assert.Equal(t, "pr-1", list.PullRequests[0].PullRequestID)
+
+	rec = c.do(http.MethodGet, "/v2/pull-requests?order=sideways", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = c.do(http.MethodPost, "/v2/pull-requests/pr-1/reviewers/u2:reassign", "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "NO_CANDIDATE", errorCode(t, rec))
+
+	require.Equal(t, http.StatusOK, c.do(http.MethodPatch, "/v2/users/u3", `{"is_active":true}`).Code)
+
+	rec = c.do(http.MethodPost, "/v2/pull-requests/pr-1/reviewers/u2:reassign", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var reassigned struct {
+		PullRequest models.PullRequest `json:"pull_request"`
+		ReplacedBy  string             `json:"replaced_by"`
+	}
+	decode(t, rec, &reassigned)
+	assert.Equal(t, "u3", reassigned.ReplacedBy)
+	assert.Equal(t, []string{"u3"}, reassigned.PullRequest.AssignedReviewers)
+
+	rec = c.do(http.MethodPost, "/v2/pull-requests/pr-1/reviewers/u3:review", `{"decision":"APPROVED"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	decode(t, rec, &pr)
+	require.Len(t, pr.Reviewers, 1)
+	require.NotNil(t, pr.Reviewers[0].Decision)
+	assert.Equal(t, models.DecisionApproved, *pr.Reviewers[0].Decision)
+
+	rec = c.do(http.MethodPost, "/v2/pull-requests/pr-1/reviewers/u2:review", `{"decision":"APPROVED"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "NOT_ASSIGNED", errorCode(t, rec))
+
+	rec = c.do(http.MethodPost, "/v2/pull-requests/pr-1/merge", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	decode(t, rec, &pr)
+	assert.Equal(t, "MERGED", pr.Status)
+
+	rec = c.do(http.MethodPost, "/v2/pull-requests/pr-1/reviewers/u3:reassign", "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "PR_MERGED", errorCode(t, rec))
+
+	rec = c.do(http.MethodPost, "/v2/pull-requests/missing/merge", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "PR_NOT_FOUND", errorCode(t, rec))
+}
+
+func TestAPIKeys(t *testing.T) {
+	c := newClient(t)
+	createBackendTeam(c)
+
+	rec := c.do(http.MethodPost, "/v2/api-keys", `{"name":"dashboards","role":"read_only"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created struct {
+		APIKey models.APIKey `json:"api_key"`
+		Key    string        `json:"key"`
+	}
+	decode(t, rec, &created)
+	assert.Equal(t, "/v2/api-keys/"+created.APIKey.KeyID, rec.Header().Get("Location"))
+
+	rec = c.do(http.MethodGet, "/v2/api-keys", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), created.APIKey.KeyID)
+
+	readOnly := &client{t: t, router: c.router, key: created.Key}
+	assert.Equal(t, http.StatusOK, readOnly.do(http.MethodGet, "/v2/teams/backend", "").Code)
+	rec = readOnly.do(http.MethodPost, "/v2/teams", `{"team_name":"frontend","members":[]}`)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = c.do(http.MethodDelete, "/v2/api-keys/"+created.APIKey.KeyID, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	assert.Equal(t, http.StatusUnauthorized, readOnly.do(http.MethodGet, "/v2/teams/backend", "").Code)
+
+	rec = c.do(http.MethodDelete, "/v2/api-keys/missing", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestV2RequiresCredentials(t *testing.T) {
+	c := newClient(t)
+	c.key = ""
+
+	rec := c.do(http.MethodGet, "/v2/teams/backend", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("X-Request-ID"))
+}
+
+func TestV1UnchangedAlongsideV2(t *testing.T) {
+	c := newClient(t)
+	createBackendTeam(c)
+
+	rec := c.do(http.MethodGet, "/team/get?team_name=backend", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = c.do(http.MethodPost, "/team/add", `{"team_name":"backend","members":[]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "TEAM_EXISTS", errorCode(t, rec))
+
+	rec = c.do(http.MethodPost, "/pullRequest/create", `{"pull_request_id":"pr-1","pull_request_name":"Feature","author_id":"u1"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Empty(t, rec.Header().Get("Location"))
+
+	rec = c.do(http.MethodGet, "/v2/pull-requests/pr-1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
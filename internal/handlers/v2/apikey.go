@@ -0,0 +1,63 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+)
+
+type APIKeyHandler struct {
+	service *service.APIKeyService
+}
+
+// NewAPIKeyHandler manages stored keys only; the bootstrap key is checked by
+// v1's authentication middleware and never listed.
+func NewAPIKeyHandler(repo service.Store) *APIKeyHandler {
+	return &APIKeyHandler{service: service.NewAPIKeyService(repo, "")}
+}
+
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAPIKeyRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	key, rawKey, err := h.service.CreateKey(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	// The response carries the only copy of the key; keep it out of caches
+	// and the idempotency store.
+	w.Header().Set("Cache-Control", "no-store")
+	writeCreated(w, r, "/api-keys", key.KeyID, map[string]interface{}{
+		"api_key": key,
+		"key":     rawKey,
+	})
+}
+
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.ListKeys(r.Context())
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, statusOK, map[string]interface{}{
+		"api_keys": keys,
+	})
+}
+
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.RevokeKey(r.Context(), mux.Vars(r)["id"]); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(statusNoContent)
+}
@@ -0,0 +1,13 @@
+package v2
+
+import "net/http"
+
+const pathPrefix = "/v2"
+
+const (
+	statusOK        = http.StatusOK
+	statusCreated   = http.StatusCreated
+	statusNoContent = http.StatusNoContent
+	statusConflict  = http.StatusConflict
+	statusForbidden = http.StatusForbidden
+)
@@ -0,0 +1,132 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+)
+
+type PRHandler struct {
+	service *service.PRService
+}
+
+func NewPRHandler(repo service.Store) *PRHandler {
+	return &PRHandler{service: service.NewPRService(repo)}
+}
+
+func (h *PRHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
+	var req models.CreatePRRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	pr, err := h.service.CreatePR(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeCreated(w, r, "/pull-requests", pr.PullRequestID, pr)
+}
+
+func (h *PRHandler) ListPRs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := httpx.ParsePRListFilter(query)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	resp, err := h.service.ListPRs(r.Context(), filter, query.Get("cursor"))
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, statusOK, resp)
+}
+
+func (h *PRHandler) GetPR(w http.ResponseWriter, r *http.Request) {
+	pr, err := h.service.GetPR(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, statusOK, pr)
+}
+
+func (h *PRHandler) MergePR(w http.ResponseWriter, r *http.Request) {
+	pr, err := h.service.MergePR(r.Context(), models.MergePRRequest{
+		PullRequestID: mux.Vars(r)["id"],
+	})
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, statusOK, pr)
+}
+
+func (h *PRHandler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	req := models.ReassignPRRequest{
+		PullRequestID: vars["id"],
+		OldUserID:     vars["uid"],
+	}
+
+	if !httpx.CanActAsUser(r, req.OldUserID) {
+		httpx.WriteError(w, statusForbidden, httpx.ErrorCodeForbidden, httpx.ErrorMsgReassignSelfOnly)
+		return
+	}
+
+	pr, newReviewerID, err := h.service.ReassignPR(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, statusOK, map[string]interface{}{
+		"pull_request": pr,
+		"replaced_by":  newReviewerID,
+	})
+}
+
+// reviewRequest is the body of POST
+// /v2/pull-requests/{id}/reviewers/{uid}:review.
+type reviewRequest struct {
+	Decision string `json:"decision"`
+}
+
+func (h *PRHandler) ReviewPR(w http.ResponseWriter, r *http.Request) {
+	var body reviewRequest
+	if err := httpx.DecodeJSON(r, &body); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	req := models.ReviewPRRequest{
+		PullRequestID: vars["id"],
+		ReviewerID:    vars["uid"],
+		Decision:      body.Decision,
+	}
+
+	if !httpx.CanActAsUser(r, req.ReviewerID) {
+		httpx.WriteError(w, statusForbidden, httpx.ErrorCodeForbidden, httpx.ErrorMsgReviewSelfOnly)
+		return
+	}
+
+	pr, err := h.service.ReviewPR(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, statusOK, pr)
+}
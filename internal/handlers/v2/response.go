@@ -0,0 +1,36 @@
+package v2
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+)
+
+// handleServiceError answers like v1, except for the statuses v1 keeps only
+// for compatibility: an existing team is a conflict rather than a bad request.
+func handleServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, service.ErrTeamExists) {
+		httpx.WriteError(w, statusConflict, service.ErrTeamExists.Code, service.ErrTeamExists.Message)
+		return
+	}
+	httpx.HandleServiceError(w, r, err)
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+// writeCreated answers 201 with the Location of the new resource.
+func writeCreated(w http.ResponseWriter, r *http.Request, collection, id string, body interface{}) {
+	w.Header().Set("Location", pathPrefix+collection+"/"+url.PathEscape(id))
+	writeJSON(w, r, statusCreated, body)
+}
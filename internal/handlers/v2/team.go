@@ -0,0 +1,44 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+)
+
+type TeamHandler struct {
+	service *service.TeamService
+}
+
+func NewTeamHandler(repo service.Store) *TeamHandler {
+	return &TeamHandler{service: service.NewTeamService(repo)}
+}
+
+func (h *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateTeamRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	team, err := h.service.AddTeam(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeCreated(w, r, "/teams", team.TeamName, team)
+}
+
+func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
+	team, err := h.service.GetTeam(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, statusOK, team)
+}
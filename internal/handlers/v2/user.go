@@ -0,0 +1,69 @@
+package v2
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/milyrock/PR-Reviewer/internal/handlers/httpx"
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+)
+
+type UserHandler struct {
+	service *service.UserService
+}
+
+func NewUserHandler(repo service.Store) *UserHandler {
+	return &UserHandler{service: service.NewUserService(repo)}
+}
+
+// updateUserRequest is the body of PATCH /v2/users/{id}. is_active is the
+// only mutable field and must be present.
+type updateUserRequest struct {
+	IsActive *bool `json:"is_active"`
+}
+
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	var req updateUserRequest
+	if err := httpx.DecodeJSON(r, &req); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+	if req.IsActive == nil {
+		handleServiceError(w, r, service.NewValidationError(models.FieldError{Field: "is_active", Message: "is required"}))
+		return
+	}
+
+	user, err := h.service.SetIsActive(r.Context(), models.SetIsActiveRequest{
+		UserID:   mux.Vars(r)["id"],
+		IsActive: *req.IsActive,
+	})
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, statusOK, user)
+}
+
+func (h *UserHandler) GetReviews(w http.ResponseWriter, r *http.Request) {
+	prs, err := h.service.GetReview(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, statusOK, map[string]interface{}{
+		"pull_requests": prs,
+	})
+}
+
+func (h *UserHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	dashboard, err := h.service.GetDashboard(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, statusOK, dashboard)
+}
@@ -0,0 +1,79 @@
+// Package logging carries per-request attributes, such as the request ID and
+// the caller, through the context so that every log line written with a
+// request context is tagged with them, whichever layer writes it.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+const RequestIDKey = "request_id"
+
+type fieldsKey struct{}
+
+type fields struct {
+	mu        sync.Mutex
+	requestID string
+	attrs     []slog.Attr
+}
+
+// WithRequestID starts the request scope. Attributes added later with AddAttrs
+// are visible through the returned context and every context derived from it.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, &fields{requestID: requestID})
+}
+
+// RequestID returns the ID set by WithRequestID, or "" outside a request.
+func RequestID(ctx context.Context) string {
+	if f := fromContext(ctx); f != nil {
+		return f.requestID
+	}
+	return ""
+}
+
+// AddAttrs tags the rest of the request with attrs. It is a no-op outside a
+// request scope.
+func AddAttrs(ctx context.Context, attrs ...slog.Attr) {
+	f := fromContext(ctx)
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attrs = append(f.attrs, attrs...)
+}
+
+func fromContext(ctx context.Context) *fields {
+	f, _ := ctx.Value(fieldsKey{}).(*fields)
+	return f
+}
+
+// Handler adds the request scope of the record's context to every record.
+type Handler struct {
+	slog.Handler
+}
+
+func NewHandler(h slog.Handler) *Handler {
+	return &Handler{Handler: h}
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if f := fromContext(ctx); f != nil {
+		f.mu.Lock()
+		record.AddAttrs(slog.String(RequestIDKey, f.requestID))
+		record.AddAttrs(f.attrs...)
+		f.mu.Unlock()
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(NewHandler(slog.NewJSONHandler(buf, nil)))
+}
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	buf.Reset()
+	return line
+}
+
+func TestHandlerTagsRequestScope(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf).With("component", "test")
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	assert.Equal(t, "req-1", RequestID(ctx))
+
+	logger.InfoContext(ctx, "before auth")
+	line := decode(t, &buf)
+	assert.Equal(t, "req-1", line[RequestIDKey])
+	assert.Equal(t, "test", line["component"])
+	assert.NotContains(t, line, "caller")
+
+	// Attributes added further down the chain reach loggers holding the outer
+	// context too.
+	inner := context.WithValue(ctx, struct{}{}, "inner")
+	AddAttrs(inner, slog.String("caller", "key-1"))
+
+	logger.InfoContext(ctx, "after auth")
+	assert.Equal(t, "key-1", decode(t, &buf)["caller"])
+}
+
+func TestHandlerOutsideRequest(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf)
+
+	ctx := context.Background()
+	AddAttrs(ctx, slog.String("caller", "key-1"))
+	assert.Empty(t, RequestID(ctx))
+
+	logger.InfoContext(ctx, "background")
+	line := decode(t, &buf)
+	assert.NotContains(t, line, RequestIDKey)
+	assert.NotContains(t, line, "caller")
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "prreviewer"
+
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	ReviewerAssignmentsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reviewer_assignments_total",
+		Help:      "Number of reviewers assigned to pull requests, including reassignments.",
+	})
+
+	ReviewerReassignmentsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reviewer_reassignments_total",
+		Help:      "Number of successful reviewer reassignments.",
+	})
+
+	NoCandidateTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "no_candidate_total",
+		Help:      "Number of reassignments that failed with NO_CANDIDATE.",
+	})
+
+	ReviewersPerPR = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reviewers_per_pull_request",
+		Help:      "Number of reviewers assigned when a pull request is created.",
+		Buckets:   []float64{0, 1, 2},
+	})
+)
+
+type OpenPRCounter interface {
+	CountOpenPRsByTeam(ctx context.Context) (map[string]int, error)
+}
+
+// RegisterDB registers connection pool statistics for db, labelled with
+// dbName, and the open PRs per team gauge, which is computed from counter on
+// every scrape.
+func RegisterDB(db *sql.DB, dbName string, counter OpenPRCounter) error {
+	if err := prometheus.Register(collectors.NewDBStatsCollector(db, dbName)); err != nil {
+		return err
+	}
+
+	return prometheus.Register(&openPRCollector{counter: counter})
+}
+
+// collectTimeout bounds the open PR query so a slow database cannot stall a
+// scrape indefinitely.
+const collectTimeout = 5 * time.Second
+
+var openPRsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "open_pull_requests"),
+	"Number of open pull requests by author team.",
+	[]string{"team"}, nil,
+)
+
+type openPRCollector struct {
+	counter OpenPRCounter
+}
+
+func (c *openPRCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- openPRsDesc
+}
+
+func (c *openPRCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	counts, err := c.counter.CountOpenPRsByTeam(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(openPRsDesc, err)
+		return
+	}
+
+	for team, count := range counts {
+		ch <- prometheus.MustNewConstMetric(openPRsDesc, prometheus.GaugeValue, float64(count), team)
+	}
+}
@@ -21,15 +21,32 @@ type User struct {
 }
 
 type PullRequest struct {
-	PullRequestID     string     `json:"pull_request_id" db:"pull_request_id"`
-	PullRequestName   string     `json:"pull_request_name" db:"pull_request_name"`
-	AuthorID          string     `json:"author_id" db:"author_id"`
-	Status            string     `json:"status" db:"status"`
-	AssignedReviewers []string   `json:"assigned_reviewers"`
-	CreatedAt         *time.Time `json:"createdAt,omitempty" db:"created_at"`
-	MergedAt          *time.Time `json:"mergedAt,omitempty" db:"merged_at"`
+	PullRequestID     string       `json:"pull_request_id" db:"pull_request_id"`
+	PullRequestName   string       `json:"pull_request_name" db:"pull_request_name"`
+	AuthorID          string       `json:"author_id" db:"author_id"`
+	Status            string       `json:"status" db:"status"`
+	AssignedReviewers []string     `json:"assigned_reviewers"`
+	Reviewers         []PRReviewer `json:"reviewers,omitempty"`
+	CreatedAt         *time.Time   `json:"createdAt,omitempty" db:"created_at"`
+	UpdatedAt         *time.Time   `json:"updatedAt,omitempty" db:"updated_at"`
+	MergedAt          *time.Time   `json:"mergedAt,omitempty" db:"merged_at"`
 }
 
+type PRReviewer struct {
+	UserID     string     `json:"user_id" db:"user_id"`
+	Username   string     `json:"username" db:"username"`
+	TeamName   string     `json:"team_name" db:"team_name"`
+	IsActive   bool       `json:"is_active" db:"is_active"`
+	AssignedAt time.Time  `json:"assigned_at" db:"assigned_at"`
+	Decision   *string    `json:"decision,omitempty" db:"decision"`
+	DecidedAt  *time.Time `json:"decided_at,omitempty" db:"decided_at"`
+}
+
+const (
+	DecisionApproved         = "APPROVED"
+	DecisionChangesRequested = "CHANGES_REQUESTED"
+)
+
 type PullRequestShort struct {
 	PullRequestID   string `json:"pull_request_id" db:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name" db:"pull_request_name"`
@@ -37,13 +54,121 @@ type PullRequestShort struct {
 	Status          string `json:"status" db:"status"`
 }
 
+type PRListFilter struct {
+	Status      string
+	AuthorID    string
+	ReviewerID  string
+	TeamName    string
+	Name        string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MergedFrom  *time.Time
+	MergedTo    *time.Time
+	SortAsc     bool
+	After       *PRCursor
+	Limit       int
+}
+
+type PRCursor struct {
+	CreatedAt     time.Time `json:"created_at"`
+	PullRequestID string    `json:"pull_request_id"`
+}
+
+type PRListResponse struct {
+	PullRequests []PullRequest `json:"pull_requests"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+}
+
+type UserDashboard struct {
+	UserID         string             `json:"user_id"`
+	ToReview       []PullRequestShort `json:"to_review"`
+	Authored       []PullRequest      `json:"authored"`
+	RecentlyMerged []PullRequest      `json:"recently_merged"`
+}
+
+const (
+	RoleAdmin       = "admin"
+	RoleIntegration = "integration"
+	RoleReadOnly    = "read_only"
+)
+
+type APIKey struct {
+	KeyID     string     `json:"key_id" db:"key_id"`
+	Name      string     `json:"name" db:"name"`
+	Role      string     `json:"role" db:"role"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type RevokeAPIKeyRequest struct {
+	KeyID string `json:"key_id"`
+}
+
+type IdempotencyRecord struct {
+	Key         string `db:"idempotency_key"`
+	RequestHash string `db:"request_hash"`
+	StatusCode  *int   `db:"status_code"`
+	// ResponseHeaders is a JSON object of the response headers to replay.
+	ResponseHeaders []byte `db:"response_headers"`
+	ResponseBody    []byte `db:"response_body"`
+}
+
+// SchemaVersion compares the version recorded in the database with the one
+// the running binary's migrations create.
+type SchemaVersion struct {
+	Current  int `json:"current"`
+	Expected int `json:"expected"`
+}
+
+// WorkerHeartbeat records the runs of a background job. LastError is the raw
+// error of the last run and must not be shown to clients as is.
+type WorkerHeartbeat struct {
+	Name      string
+	Interval  time.Duration
+	StartedAt time.Time
+	LastRunAt *time.Time
+	LastError string
+}
+
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+)
+
+type HealthCheck struct {
+	Name      string      `json:"name"`
+	Status    string      `json:"status"`
+	LatencyMS float64     `json:"latency_ms"`
+	Error     string      `json:"error,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+}
+
+type HealthReport struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
 type ErrorResponse struct {
 	Error struct {
-		Code    string `json:"code"`
-		Message string `json:"message"`
+		Code      string       `json:"code"`
+		Message   string       `json:"message"`
+		RequestID string       `json:"request_id,omitempty"`
+		Details   []FieldError `json:"details,omitempty"`
 	} `json:"error"`
 }
 
+// FieldError describes one invalid field of a request. Field is the JSON path,
+// e.g. "members[1].user_id".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
 type CreateTeamRequest struct {
 	TeamName string       `json:"team_name"`
 	Members  []TeamMember `json:"members"`
@@ -69,6 +194,12 @@ type ReassignPRRequest struct {
 	OldUserID     string `json:"old_reviewer_id"`
 }
 
+type ReviewPRRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+	Decision      string `json:"decision"`
+}
+
 type UserReviewStats struct {
 	UserID      string `json:"user_id" db:"user_id"`
 	Username    string `json:"username" db:"username"`
@@ -81,7 +212,114 @@ type PRReviewStats struct {
 	ReviewerCount   int    `json:"reviewer_count" db:"reviewer_count"`
 }
 
+type StatisticsFilter struct {
+	From     *time.Time
+	To       *time.Time
+	TeamName string
+	Status   string
+	Bucket   string
+}
+
+type StatisticsBucket struct {
+	PeriodStart time.Time `json:"period_start" db:"period_start"`
+	PRsCreated  int       `json:"prs_created" db:"prs_created"`
+	PRsMerged   int       `json:"prs_merged" db:"prs_merged"`
+	Assignments int       `json:"assignments" db:"assignments"`
+}
+
+type LatencyStats struct {
+	Key        string  `json:"key" db:"key"`
+	Count      int     `json:"count" db:"count"`
+	P50Seconds float64 `json:"p50_seconds" db:"p50"`
+	P90Seconds float64 `json:"p90_seconds" db:"p90"`
+	P99Seconds float64 `json:"p99_seconds" db:"p99"`
+}
+
+type LatencyResponse struct {
+	TimeToMergeByTeam           []LatencyStats `json:"time_to_merge_by_team"`
+	TimeToMergeByAuthor         []LatencyStats `json:"time_to_merge_by_author"`
+	TimeToMergeByReviewer       []LatencyStats `json:"time_to_merge_by_reviewer"`
+	TimeToMergeByWeek           []LatencyStats `json:"time_to_merge_by_week"`
+	AssignmentToMergeByReviewer []LatencyStats `json:"assignment_to_merge_by_reviewer"`
+	AssignmentToMergeByWeek     []LatencyStats `json:"assignment_to_merge_by_week"`
+	// AssignmentToFirstDecision* measure from assignment to the reviewer's
+	// first APPROVED or CHANGES_REQUESTED decision, merged or not.
+	AssignmentToFirstDecisionByReviewer []LatencyStats `json:"assignment_to_first_decision_by_reviewer"`
+	AssignmentToFirstDecisionByWeek     []LatencyStats `json:"assignment_to_first_decision_by_week"`
+}
+
+type MemberAssignments struct {
+	UserID      string    `db:"user_id"`
+	Username    string    `db:"username"`
+	TeamName    string    `db:"team_name"`
+	CreatedAt   time.Time `db:"created_at"`
+	Assignments int       `db:"assignments"`
+}
+
+type MemberLoad struct {
+	UserID            string  `json:"user_id"`
+	Username          string  `json:"username"`
+	Assignments       int     `json:"assignments"`
+	DaysActive        float64 `json:"days_active"`
+	AssignmentsPerDay float64 `json:"assignments_per_day"`
+}
+
+// TeamFairness describes how evenly a team shares reviews. The spread
+// (standard deviation and Gini) is taken over assignments per day active, so
+// that a member who joined during the window is not counted as underloaded.
+// A member appears in at most one of MostLoaded and LeastLoaded.
+type TeamFairness struct {
+	TeamName                string       `json:"team_name"`
+	ActiveMembers           int          `json:"active_members"`
+	TotalAssignments        int          `json:"total_assignments"`
+	MeanAssignments         float64      `json:"mean_assignments"`
+	MeanAssignmentsPerDay   float64      `json:"mean_assignments_per_day"`
+	StdDevAssignmentsPerDay float64      `json:"stddev_assignments_per_day"`
+	Gini                    float64      `json:"gini"`
+	MostLoaded              []MemberLoad `json:"most_loaded"`
+	LeastLoaded             []MemberLoad `json:"least_loaded"`
+	Members                 []MemberLoad `json:"members"`
+}
+
+type FairnessResponse struct {
+	From  time.Time      `json:"from"`
+	To    time.Time      `json:"to"`
+	Teams []TeamFairness `json:"teams"`
+}
+
+type GraphNode struct {
+	UserID   string `json:"id" db:"user_id"`
+	Username string `json:"username" db:"username"`
+	TeamName string `json:"team_name" db:"team_name"`
+}
+
+type GraphEdge struct {
+	AuthorID   string `json:"source" db:"author_id"`
+	ReviewerID string `json:"target" db:"reviewer_id"`
+	Weight     int    `json:"weight" db:"weight"`
+}
+
+type AuthorBusFactor struct {
+	AuthorID  string `json:"author_id"`
+	Reviewers int    `json:"reviewers"`
+}
+
+type TeamBusFactor struct {
+	TeamName     string            `json:"team_name"`
+	MinBusFactor int               `json:"min_bus_factor"`
+	AvgBusFactor float64           `json:"avg_bus_factor"`
+	Authors      []AuthorBusFactor `json:"authors"`
+}
+
+type CollaborationGraph struct {
+	Nodes       []GraphNode     `json:"nodes"`
+	Edges       []GraphEdge     `json:"edges"`
+	Reciprocity float64         `json:"reciprocity"`
+	BusFactor   []TeamBusFactor `json:"bus_factor"`
+}
+
 type StatisticsResponse struct {
-	UserStats []UserReviewStats `json:"user_stats"`
-	PRStats   []PRReviewStats   `json:"pr_stats"`
+	UserStats []UserReviewStats  `json:"user_stats"`
+	PRStats   []PRReviewStats    `json:"pr_stats"`
+	Timeline  []StatisticsBucket `json:"timeline,omitempty"`
 }
@@ -0,0 +1,105 @@
+// Package aggregate holds the statistics computations that Postgres does in
+// SQL but that the other store implementations have to do in Go.
+package aggregate
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+// Truncate mirrors date_trunc for the "day" and "week" units in a UTC
+// session. Weeks start on Monday.
+func Truncate(t time.Time, unit string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if unit == "week" {
+		offset := (int(day.Weekday()) + 6) % 7
+		day = day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+// Week formats the start of the week containing t as the latency queries do.
+func Week(t time.Time) string {
+	return Truncate(t, "week").Format("2006-01-02")
+}
+
+// Sample is one duration observation for a latency group.
+type Sample struct {
+	Key     string
+	Seconds float64
+}
+
+// Latency groups samples by key and computes p50/p90/p99 with the semantics of
+// percentile_cont, ordered by key.
+func Latency(samples []Sample) []models.LatencyStats {
+	groups := make(map[string][]float64)
+	for _, sample := range samples {
+		groups[sample.Key] = append(groups[sample.Key], sample.Seconds)
+	}
+
+	var stats []models.LatencyStats
+	for key, durations := range groups {
+		sort.Float64s(durations)
+		stats = append(stats, models.LatencyStats{
+			Key:        key,
+			Count:      len(durations),
+			P50Seconds: PercentileCont(durations, 0.5),
+			P90Seconds: PercentileCont(durations, 0.9),
+			P99Seconds: PercentileCont(durations, 0.99),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Key < stats[j].Key })
+
+	return stats
+}
+
+// PercentileCont interpolates linearly between the closest ranks of sorted.
+func PercentileCont(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	pos := p * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+
+	return sorted[lo] + (pos-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+// PRActivity is the per-PR input of Timeline.
+type PRActivity struct {
+	CreatedAt time.Time `db:"created_at"`
+	Status    string    `db:"status"`
+	Reviewers int       `db:"reviewers"`
+}
+
+// Timeline buckets PRs by creation time like the Postgres timeline query.
+func Timeline(prs []PRActivity, unit string) []models.StatisticsBucket {
+	buckets := make(map[time.Time]*models.StatisticsBucket)
+	for _, pr := range prs {
+		start := Truncate(pr.CreatedAt, unit)
+		bucket, ok := buckets[start]
+		if !ok {
+			bucket = &models.StatisticsBucket{PeriodStart: start}
+			buckets[start] = bucket
+		}
+
+		bucket.PRsCreated++
+		if pr.Status == "MERGED" {
+			bucket.PRsMerged++
+		}
+		bucket.Assignments += pr.Reviewers
+	}
+
+	var result []models.StatisticsBucket
+	for _, bucket := range buckets {
+		result = append(result, *bucket)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].PeriodStart.Before(result[j].PeriodStart) })
+
+	return result
+}
@@ -0,0 +1,42 @@
+package aggregate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentileCont(t *testing.T) {
+	assert.Equal(t, 0.0, PercentileCont(nil, 0.5))
+	assert.Equal(t, 7.0, PercentileCont([]float64{7}, 0.99))
+	assert.InDelta(t, 2.5, PercentileCont([]float64{1, 2, 3, 4}, 0.5), 1e-9)
+	assert.InDelta(t, 3.7, PercentileCont([]float64{1, 2, 3, 4}, 0.9), 1e-9)
+}
+
+func TestTruncate(t *testing.T) {
+	sunday := time.Date(2024, 3, 10, 23, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), Truncate(sunday, "day"))
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), Truncate(sunday, "week"))
+	assert.Equal(t, "2024-03-11", Week(sunday.Add(time.Hour)))
+}
+
+func TestTimeline(t *testing.T) {
+	monday := time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC)
+
+	buckets := Timeline([]PRActivity{
+		{CreatedAt: monday.Add(24 * time.Hour), Status: "OPEN", Reviewers: 1},
+		{CreatedAt: monday, Status: "MERGED", Reviewers: 2},
+		{CreatedAt: monday.Add(time.Hour), Status: "OPEN", Reviewers: 0},
+	}, "day")
+
+	if assert.Len(t, buckets, 2) {
+		assert.Equal(t, 2, buckets[0].PRsCreated)
+		assert.Equal(t, 1, buckets[0].PRsMerged)
+		assert.Equal(t, 2, buckets[0].Assignments)
+		assert.Equal(t, 1, buckets[1].PRsCreated)
+	}
+
+	assert.Len(t, Timeline(nil, "week"), 0)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+const (
+	insertAPIKey = `
+		INSERT INTO api_keys (key_id, name, key_hash, role, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	selectAPIKeyByHash = `
+		SELECT key_id, name, role, created_at, revoked_at
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`
+
+	selectAPIKeys = `
+		SELECT key_id, name, role, created_at, revoked_at
+		FROM api_keys
+		ORDER BY created_at, key_id
+	`
+
+	revokeAPIKey = `
+		UPDATE api_keys
+		SET revoked_at = $1
+		WHERE key_id = $2 AND revoked_at IS NULL
+	`
+)
+
+func (r *Repository) CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	_, err := r.db.ExecContext(ctx, insertAPIKey, key.KeyID, key.Name, keyHash, key.Role, key.CreatedAt)
+	if isUniqueViolation(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *Repository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.GetContext(ctx, &key, selectAPIKeyByHash, keyHash); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *Repository) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.SelectContext(ctx, &keys, selectAPIKeys)
+	return keys, err
+}
+
+func (r *Repository) RevokeAPIKey(ctx context.Context, keyID string) error {
+	result, err := r.db.ExecContext(ctx, revokeAPIKey, time.Now(), keyID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgconn"
+)
+
+// ErrDuplicate is returned when an insert violates a unique constraint.
+var ErrDuplicate = errors.New("duplicate key")
+
+// ErrNotAssigned is returned when a reviewer to be replaced is no longer
+// assigned to the PR.
+var ErrNotAssigned = errors.New("reviewer not assigned")
+
+const (
+	pgUniqueViolation = "23505"
+	pgQueryCanceled   = "57014"
+)
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
+
+// IsTimeout reports whether err was caused by the request deadline or by the
+// database cancelling a statement that exceeded statement_timeout.
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgQueryCanceled
+}
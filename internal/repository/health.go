@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+// SchemaVersion is the version db/pr.sql records in schema_version. Bump both
+// together.
+const SchemaVersion = 3
+
+const selectSchemaVersion = `SELECT COALESCE(MAX(version), 0) FROM schema_version`
+
+func (r *Repository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+func (r *Repository) SchemaVersion(ctx context.Context) (models.SchemaVersion, error) {
+	version := models.SchemaVersion{Expected: SchemaVersion}
+	err := r.db.GetContext(ctx, &version.Current, selectSchemaVersion)
+	return version, err
+}
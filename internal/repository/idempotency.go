@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+const (
+	deleteExpiredIdempotencyKey = `
+		DELETE FROM idempotency_records
+		WHERE idempotency_key = $1 AND expires_at < $2
+	`
+
+	insertIdempotencyKey = `
+		INSERT INTO idempotency_records (idempotency_key, request_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (idempotency_key) DO NOTHING
+	`
+
+	selectIdempotencyKey = `
+		SELECT idempotency_key, request_hash, status_code, response_headers, response_body
+		FROM idempotency_records
+		WHERE idempotency_key = $1
+	`
+
+	completeIdempotencyKey = `
+		UPDATE idempotency_records
+		SET status_code = $2, response_headers = $3, response_body = $4, expires_at = $5
+		WHERE idempotency_key = $1
+	`
+
+	deleteIdempotencyKey = `DELETE FROM idempotency_records WHERE idempotency_key = $1`
+
+	deleteExpiredIdempotencyKeys = `DELETE FROM idempotency_records WHERE expires_at < $1`
+)
+
+// ReserveIdempotencyKey claims key for a new request for the duration of
+// lease. It returns true when the key was free (or had expired); otherwise the
+// caller should inspect the existing record with GetIdempotencyRecord.
+func (r *Repository) ReserveIdempotencyKey(ctx context.Context, key, requestHash string, lease time.Duration) (bool, error) {
+	now := time.Now()
+
+	if _, err := r.db.ExecContext(ctx, deleteExpiredIdempotencyKey, key, now); err != nil {
+		return false, err
+	}
+
+	result, err := r.db.ExecContext(ctx, insertIdempotencyKey, key, requestHash, now, now.Add(lease))
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (r *Repository) GetIdempotencyRecord(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	var record models.IdempotencyRecord
+	if err := r.db.GetContext(ctx, &record, selectIdempotencyKey, key); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// CompleteIdempotencyKey stores the response and keeps it for ttl.
+func (r *Repository) CompleteIdempotencyKey(ctx context.Context, key string, statusCode int, headers, body []byte, ttl time.Duration) error {
+	result, err := r.db.ExecContext(ctx, completeIdempotencyKey, key, statusCode, headers, body, time.Now().Add(ttl))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, deleteIdempotencyKey, key)
+	return err
+}
+
+func (r *Repository) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	result, err := r.db.ExecContext(ctx, deleteExpiredIdempotencyKeys, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
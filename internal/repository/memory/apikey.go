@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository"
+)
+
+func (s *Store) CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+
+	if _, ok := s.apiKeys[key.KeyID]; ok {
+		return repository.ErrDuplicate
+	}
+	for _, existing := range s.apiKeys {
+		if existing.hash == keyHash {
+			return repository.ErrDuplicate
+		}
+	}
+
+	row := &apiKeyRow{APIKey: *key, hash: keyHash}
+	row.CreatedAt = row.CreatedAt.Truncate(time.Microsecond)
+	row.RevokedAt = nil
+	s.apiKeys[key.KeyID] = row
+
+	return nil
+}
+
+func (s *Store) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	for _, row := range s.apiKeys {
+		if row.hash == keyHash && row.RevokedAt == nil {
+			key := row.APIKey
+			return &key, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (s *Store) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	var keys []models.APIKey
+	for _, row := range s.apiKeys {
+		keys = append(keys, row.APIKey)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].KeyID < keys[j].KeyID
+	})
+
+	return keys, nil
+}
+
+func (s *Store) RevokeAPIKey(ctx context.Context, keyID string) error {
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+
+	row, ok := s.apiKeys[keyID]
+	if !ok || row.RevokedAt != nil {
+		return sql.ErrNoRows
+	}
+
+	row.RevokedAt = timePtr(s.now())
+	return nil
+}
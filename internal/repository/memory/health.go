@@ -0,0 +1,25 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository"
+)
+
+func (s *Store) Ping(ctx context.Context) error {
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// SchemaVersion always matches: the in-memory layout is compiled into the
+// binary.
+func (s *Store) SchemaVersion(ctx context.Context) (models.SchemaVersion, error) {
+	if err := s.Ping(ctx); err != nil {
+		return models.SchemaVersion{}, err
+	}
+	return models.SchemaVersion{Current: repository.SchemaVersion, Expected: repository.SchemaVersion}, nil
+}
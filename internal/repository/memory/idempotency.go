@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+func (s *Store) ReserveIdempotencyKey(ctx context.Context, key, requestHash string, lease time.Duration) (bool, error) {
+	if err := s.lock(ctx); err != nil {
+		return false, err
+	}
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if existing, ok := s.idempotency[key]; ok {
+		if !existing.expiresAt.Before(now) {
+			return false, nil
+		}
+	}
+
+	s.idempotency[key] = &idempotencyRow{
+		IdempotencyRecord: models.IdempotencyRecord{Key: key, RequestHash: requestHash},
+		expiresAt:         now.Add(lease),
+	}
+
+	return true, nil
+}
+
+func (s *Store) GetIdempotencyRecord(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	row, ok := s.idempotency[key]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	record := row.IdempotencyRecord
+	record.ResponseHeaders = append([]byte(nil), row.ResponseHeaders...)
+	record.ResponseBody = append([]byte(nil), row.ResponseBody...)
+	return &record, nil
+}
+
+func (s *Store) CompleteIdempotencyKey(ctx context.Context, key string, statusCode int, headers, body []byte, ttl time.Duration) error {
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+
+	row, ok := s.idempotency[key]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	row.StatusCode = &statusCode
+	row.ResponseHeaders = append([]byte(nil), headers...)
+	row.ResponseBody = append([]byte(nil), body...)
+	row.expiresAt = s.now().Add(ttl)
+
+	return nil
+}
+
+func (s *Store) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+
+	delete(s.idempotency, key)
+	return nil
+}
+
+func (s *Store) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	if err := s.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var purged int64
+	for key, row := range s.idempotency {
+		if row.expiresAt.Before(now) {
+			delete(s.idempotency, key)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
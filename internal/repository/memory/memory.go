@@ -0,0 +1,90 @@
+// Package memory is an in-process implementation of service.Store. It mirrors
+// the semantics of the Postgres repository, including ordering, uniqueness and
+// the errors returned for missing rows, and is meant for tests and local runs
+// without a database.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+type userRow struct {
+	models.User
+	createdAt time.Time
+}
+
+type prRow struct {
+	id        string
+	name      string
+	authorID  string
+	status    string
+	createdAt time.Time
+	updatedAt time.Time
+	mergedAt  *time.Time
+	reviewers map[string]time.Time
+	decisions map[string]reviewDecision
+}
+
+type reviewDecision struct {
+	decision       string
+	decidedAt      time.Time
+	firstDecidedAt time.Time
+}
+
+type apiKeyRow struct {
+	models.APIKey
+	hash string
+}
+
+type idempotencyRow struct {
+	models.IdempotencyRecord
+	expiresAt time.Time
+}
+
+// Store keeps all data in maps guarded by a single mutex, so every method is
+// atomic with respect to the others, which gives the same isolation the
+// Postgres transactions provide.
+type Store struct {
+	mu          sync.Mutex
+	teams       map[string]bool
+	users       map[string]*userRow
+	prs         map[string]*prRow
+	apiKeys     map[string]*apiKeyRow
+	idempotency map[string]*idempotencyRow
+	now         func() time.Time
+}
+
+func New() *Store {
+	return &Store{
+		teams:       make(map[string]bool),
+		users:       make(map[string]*userRow),
+		prs:         make(map[string]*prRow),
+		apiKeys:     make(map[string]*apiKeyRow),
+		idempotency: make(map[string]*idempotencyRow),
+		now:         now,
+	}
+}
+
+// now returns the current time at the microsecond precision Postgres stores
+// timestamps with, so values read back compare equal across implementations.
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+// lock checks ctx before taking the mutex so that cancelled requests fail the
+// same way they do against the database.
+func (s *Store) lock(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	return nil
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/milyrock/PR-Reviewer/internal/repository/memory"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+	"github.com/milyrock/PR-Reviewer/internal/test/storetest"
+)
+
+var _ service.Store = (*memory.Store)(nil)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) service.Store {
+		return memory.New()
+	})
+}
@@ -0,0 +1,430 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository"
+)
+
+func (pr *prRow) short() models.PullRequestShort {
+	return models.PullRequestShort{
+		PullRequestID:   pr.id,
+		PullRequestName: pr.name,
+		AuthorID:        pr.authorID,
+		Status:          pr.status,
+	}
+}
+
+// toModel converts a row to the model returned by the repository, with the
+// reviewers ordered by user_id. The caller must hold mu.
+func (s *Store) toModel(pr *prRow) models.PullRequest {
+	result := models.PullRequest{
+		PullRequestID:   pr.id,
+		PullRequestName: pr.name,
+		AuthorID:        pr.authorID,
+		Status:          pr.status,
+		CreatedAt:       timePtr(pr.createdAt),
+		UpdatedAt:       timePtr(pr.updatedAt),
+	}
+	if pr.mergedAt != nil {
+		result.MergedAt = timePtr(*pr.mergedAt)
+	}
+
+	ids := make([]string, 0, len(pr.reviewers))
+	for id := range pr.reviewers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	result.AssignedReviewers = make([]string, 0, len(ids))
+	for _, id := range ids {
+		user := s.users[id]
+		reviewer := models.PRReviewer{
+			UserID:     id,
+			Username:   user.Username,
+			TeamName:   user.TeamName,
+			IsActive:   user.IsActive,
+			AssignedAt: pr.reviewers[id],
+		}
+		if decision, ok := pr.decisions[id]; ok {
+			reviewer.Decision = &decision.decision
+			reviewer.DecidedAt = timePtr(decision.decidedAt)
+		}
+		result.Reviewers = append(result.Reviewers, reviewer)
+		result.AssignedReviewers = append(result.AssignedReviewers, id)
+	}
+
+	return result
+}
+
+// sortedPRs returns all pull requests ordered by created_at and then id,
+// newest first unless asc is set. The caller must hold mu.
+func (s *Store) sortedPRs(asc bool) []*prRow {
+	prs := make([]*prRow, 0, len(s.prs))
+	for _, pr := range s.prs {
+		prs = append(prs, pr)
+	}
+
+	sort.Slice(prs, func(i, j int) bool {
+		less := prLess(prs[i].createdAt, prs[i].id, prs[j].createdAt, prs[j].id)
+		if asc {
+			return less
+		}
+		return prLess(prs[j].createdAt, prs[j].id, prs[i].createdAt, prs[i].id)
+	})
+
+	return prs
+}
+
+func prLess(aTime time.Time, aID string, bTime time.Time, bID string) bool {
+	if !aTime.Equal(bTime) {
+		return aTime.Before(bTime)
+	}
+	return aID < bID
+}
+
+func (s *Store) authorTeam(pr *prRow) string {
+	if author, ok := s.users[pr.authorID]; ok {
+		return author.TeamName
+	}
+	return ""
+}
+
+func (s *Store) PRExists(ctx context.Context, pullRequestID string) (bool, error) {
+	if err := s.lock(ctx); err != nil {
+		return false, err
+	}
+	defer s.mu.Unlock()
+
+	_, ok := s.prs[pullRequestID]
+	return ok, nil
+}
+
+func (s *Store) GetPR(ctx context.Context, pullRequestID string) (*models.PullRequest, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	pr, ok := s.prs[pullRequestID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	result := s.toModel(pr)
+	return &result, nil
+}
+
+func (s *Store) CreatePR(ctx context.Context, pr *models.PullRequest, choose repository.CreateChooser) error {
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+
+	author, ok := s.users[pr.AuthorID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	current := author.User
+	reviewerIDs, err := choose(&current, s.activeTeammates(author.TeamName, author.UserID))
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.prs[pr.PullRequestID]; ok {
+		return repository.ErrDuplicate
+	}
+
+	now := s.now()
+	row := &prRow{
+		id:        pr.PullRequestID,
+		name:      pr.PullRequestName,
+		authorID:  pr.AuthorID,
+		status:    pr.Status,
+		createdAt: now,
+		updatedAt: now,
+		reviewers: make(map[string]time.Time, len(reviewerIDs)),
+		decisions: make(map[string]reviewDecision),
+	}
+
+	for _, reviewerID := range reviewerIDs {
+		if _, ok := s.users[reviewerID]; !ok {
+			return fmt.Errorf("reviewer %q does not exist", reviewerID)
+		}
+		if _, ok := row.reviewers[reviewerID]; ok {
+			return repository.ErrDuplicate
+		}
+		row.reviewers[reviewerID] = now
+	}
+
+	s.prs[pr.PullRequestID] = row
+	pr.AssignedReviewers = reviewerIDs
+	return nil
+}
+
+func (s *Store) MergePR(ctx context.Context, pullRequestID string) error {
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+
+	pr, ok := s.prs[pullRequestID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	if pr.status == "OPEN" {
+		now := s.now()
+		pr.status = "MERGED"
+		pr.mergedAt = &now
+		pr.updatedAt = now
+	}
+
+	return nil
+}
+
+func (s *Store) ReassignReviewer(ctx context.Context, pullRequestID, oldUserID string, choose repository.ReassignChooser) (string, error) {
+	if err := s.lock(ctx); err != nil {
+		return "", err
+	}
+	defer s.mu.Unlock()
+
+	pr, ok := s.prs[pullRequestID]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+
+	var candidates []models.User
+	if oldUser, ok := s.users[oldUserID]; ok {
+		candidates = s.activeTeammates(oldUser.TeamName, oldUserID)
+	}
+
+	current := s.toModel(pr)
+	newUserID, err := choose(&current, candidates)
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := pr.reviewers[oldUserID]; !ok {
+		return "", repository.ErrNotAssigned
+	}
+	if _, ok := s.users[newUserID]; !ok {
+		return "", fmt.Errorf("reviewer %q does not exist", newUserID)
+	}
+	if _, ok := pr.reviewers[newUserID]; ok {
+		return "", repository.ErrDuplicate
+	}
+
+	now := s.now()
+	delete(pr.reviewers, oldUserID)
+	delete(pr.decisions, oldUserID)
+	pr.reviewers[newUserID] = now
+	pr.updatedAt = now
+
+	return newUserID, nil
+}
+
+func (s *Store) RecordReviewDecision(ctx context.Context, pullRequestID, userID, decision string) error {
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+
+	pr, ok := s.prs[pullRequestID]
+	if !ok || pr.status != "OPEN" {
+		return sql.ErrNoRows
+	}
+	if _, ok := pr.reviewers[userID]; !ok {
+		return sql.ErrNoRows
+	}
+
+	now := s.now()
+	row := reviewDecision{decision: decision, decidedAt: now, firstDecidedAt: now}
+	if previous, ok := pr.decisions[userID]; ok {
+		row.firstDecidedAt = previous.firstDecidedAt
+	}
+	pr.decisions[userID] = row
+	pr.updatedAt = now
+
+	return nil
+}
+
+func (s *Store) ListPRs(ctx context.Context, filter models.PRListFilter) ([]models.PullRequest, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	var prs []models.PullRequest
+	for _, pr := range s.sortedPRs(filter.SortAsc) {
+		if len(prs) >= filter.Limit {
+			break
+		}
+		if s.matchesPRList(pr, filter) {
+			prs = append(prs, s.toModel(pr))
+		}
+	}
+
+	return prs, nil
+}
+
+func (s *Store) matchesPRList(pr *prRow, filter models.PRListFilter) bool {
+	if _, ok := s.users[pr.authorID]; !ok {
+		return false
+	}
+	if filter.Status != "" && pr.status != filter.Status {
+		return false
+	}
+	if filter.AuthorID != "" && pr.authorID != filter.AuthorID {
+		return false
+	}
+	if filter.ReviewerID != "" {
+		if _, ok := pr.reviewers[filter.ReviewerID]; !ok {
+			return false
+		}
+	}
+	if filter.TeamName != "" && s.authorTeam(pr) != filter.TeamName {
+		return false
+	}
+	if filter.Name != "" && !strings.Contains(strings.ToLower(pr.name), strings.ToLower(filter.Name)) {
+		return false
+	}
+	if !inRange(&pr.createdAt, filter.CreatedFrom, filter.CreatedTo) {
+		return false
+	}
+	if (filter.MergedFrom != nil || filter.MergedTo != nil) && !inRange(pr.mergedAt, filter.MergedFrom, filter.MergedTo) {
+		return false
+	}
+
+	if filter.After != nil {
+		if filter.SortAsc {
+			return prLess(filter.After.CreatedAt, filter.After.PullRequestID, pr.createdAt, pr.id)
+		}
+		return prLess(pr.createdAt, pr.id, filter.After.CreatedAt, filter.After.PullRequestID)
+	}
+
+	return true
+}
+
+// inRange reports whether t lies in [from, to). A nil t never matches a bound,
+// like a NULL column in SQL.
+func inRange(t, from, to *time.Time) bool {
+	if from == nil && to == nil {
+		return true
+	}
+	if t == nil {
+		return false
+	}
+	if from != nil && t.Before(*from) {
+		return false
+	}
+	if to != nil && !t.Before(*to) {
+		return false
+	}
+	return true
+}
+
+func (s *Store) GetUserRecentlyMergedPRs(ctx context.Context, userID string, since time.Time, limit int) ([]models.PullRequest, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	var merged []*prRow
+	for _, pr := range s.prs {
+		if pr.status != "MERGED" || pr.mergedAt == nil || pr.mergedAt.Before(since) {
+			continue
+		}
+		if _, reviewed := pr.reviewers[userID]; pr.authorID != userID && !reviewed {
+			continue
+		}
+		merged = append(merged, pr)
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return prLess(*merged[j].mergedAt, merged[j].id, *merged[i].mergedAt, merged[i].id)
+	})
+
+	var prs []models.PullRequest
+	for _, pr := range merged {
+		if len(prs) >= limit {
+			break
+		}
+		prs = append(prs, s.toModel(pr))
+	}
+
+	return prs, nil
+}
+
+// StreamPRs calls fn for every pull request in creation order. The rows are
+// copied before fn is called so that fn may use the store.
+func (s *Store) StreamPRs(ctx context.Context, fn func(*models.PullRequest) error) error {
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+
+	prs := make([]models.PullRequest, 0, len(s.prs))
+	for _, pr := range s.sortedPRs(true) {
+		result := s.toModel(pr)
+		if result.Reviewers == nil {
+			result.Reviewers = []models.PRReviewer{}
+		}
+		prs = append(prs, result)
+	}
+	s.mu.Unlock()
+
+	for i := range prs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(&prs[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) CountOpenPRsByTeam(ctx context.Context) (map[string]int, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int)
+	for _, pr := range s.prs {
+		if pr.status == "OPEN" {
+			counts[s.authorTeam(pr)]++
+		}
+	}
+
+	return counts, nil
+}
+
+func (s *Store) GetPRReviewStats(ctx context.Context, filter models.StatisticsFilter) ([]models.PRReviewStats, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	var stats []models.PRReviewStats
+	for _, pr := range s.sortedPRs(false) {
+		if !s.matchesTeamStatistics(pr, filter) {
+			continue
+		}
+		stats = append(stats, models.PRReviewStats{
+			PullRequestID:   pr.id,
+			PullRequestName: pr.name,
+			ReviewerCount:   len(pr.reviewers),
+		})
+	}
+
+	return stats, nil
+}
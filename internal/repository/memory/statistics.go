@@ -0,0 +1,232 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository"
+	"github.com/milyrock/PR-Reviewer/internal/repository/aggregate"
+)
+
+// matchesPRStatistics applies the created_at window and status filter shared by
+// all statistics queries.
+func matchesPRStatistics(pr *prRow, filter models.StatisticsFilter) bool {
+	if !inRange(&pr.createdAt, filter.From, filter.To) {
+		return false
+	}
+	return filter.Status == "" || pr.status == filter.Status
+}
+
+// matchesTeamStatistics additionally restricts pr to authors of
+// filter.TeamName. The caller must hold mu.
+func (s *Store) matchesTeamStatistics(pr *prRow, filter models.StatisticsFilter) bool {
+	if _, ok := s.users[pr.authorID]; !ok {
+		return false
+	}
+	if filter.TeamName != "" && s.authorTeam(pr) != filter.TeamName {
+		return false
+	}
+	return matchesPRStatistics(pr, filter)
+}
+
+func (s *Store) GetReviewTimeline(ctx context.Context, filter models.StatisticsFilter) ([]models.StatisticsBucket, error) {
+	switch filter.Bucket {
+	case "day", "week":
+	default:
+		return nil, fmt.Errorf("unsupported bucket %q", filter.Bucket)
+	}
+
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	var prs []aggregate.PRActivity
+	for _, pr := range s.prs {
+		if s.matchesTeamStatistics(pr, filter) {
+			prs = append(prs, aggregate.PRActivity{CreatedAt: pr.createdAt, Status: pr.status, Reviewers: len(pr.reviewers)})
+		}
+	}
+
+	return aggregate.Timeline(prs, filter.Bucket), nil
+}
+
+// GetLatencyStats computes the same groups as the Postgres percentile_cont
+// queries: one sample per merged PR, or per reviewer assignment for the
+// reviewer-based metrics, or per decided assignment for the decision metrics.
+func (s *Store) GetLatencyStats(ctx context.Context, metric string, filter models.StatisticsFilter) ([]models.LatencyStats, error) {
+	switch metric {
+	case repository.LatencyTimeToMergeByTeam, repository.LatencyTimeToMergeByAuthor,
+		repository.LatencyTimeToMergeByReviewer, repository.LatencyTimeToMergeByWeek,
+		repository.LatencyAssignmentToMergeByReviewer, repository.LatencyAssignmentToMergeByWeek:
+	case repository.LatencyAssignmentToFirstDecisionByReviewer, repository.LatencyAssignmentToFirstDecisionByWeek:
+		return s.decisionLatency(ctx, metric, filter)
+	default:
+		return nil, fmt.Errorf("unsupported latency metric %q", metric)
+	}
+
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	var samples []aggregate.Sample
+	for _, pr := range s.prs {
+		if pr.status != "MERGED" || pr.mergedAt == nil {
+			continue
+		}
+		if _, ok := s.users[pr.authorID]; !ok {
+			continue
+		}
+		if !inRange(pr.mergedAt, filter.From, filter.To) {
+			continue
+		}
+		if filter.TeamName != "" && s.authorTeam(pr) != filter.TeamName {
+			continue
+		}
+
+		toMerge := pr.mergedAt.Sub(pr.createdAt).Seconds()
+		week := aggregate.Week(*pr.mergedAt)
+
+		switch metric {
+		case repository.LatencyTimeToMergeByTeam:
+			samples = append(samples, aggregate.Sample{Key: s.authorTeam(pr), Seconds: toMerge})
+		case repository.LatencyTimeToMergeByAuthor:
+			samples = append(samples, aggregate.Sample{Key: pr.authorID, Seconds: toMerge})
+		case repository.LatencyTimeToMergeByWeek:
+			samples = append(samples, aggregate.Sample{Key: week, Seconds: toMerge})
+		}
+
+		for reviewerID, assignedAt := range pr.reviewers {
+			fromAssignment := pr.mergedAt.Sub(assignedAt).Seconds()
+
+			switch metric {
+			case repository.LatencyTimeToMergeByReviewer:
+				samples = append(samples, aggregate.Sample{Key: reviewerID, Seconds: toMerge})
+			case repository.LatencyAssignmentToMergeByReviewer:
+				samples = append(samples, aggregate.Sample{Key: reviewerID, Seconds: fromAssignment})
+			case repository.LatencyAssignmentToMergeByWeek:
+				samples = append(samples, aggregate.Sample{Key: week, Seconds: fromAssignment})
+			}
+		}
+	}
+
+	return aggregate.Latency(samples), nil
+}
+
+// decisionLatency samples every reviewer assignment with a decision, from
+// assignment to the first decision, windowed on that decision.
+func (s *Store) decisionLatency(ctx context.Context, metric string, filter models.StatisticsFilter) ([]models.LatencyStats, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	var samples []aggregate.Sample
+	for _, pr := range s.prs {
+		if _, ok := s.users[pr.authorID]; !ok {
+			continue
+		}
+		if filter.TeamName != "" && s.authorTeam(pr) != filter.TeamName {
+			continue
+		}
+
+		for reviewerID, decision := range pr.decisions {
+			decidedAt := decision.firstDecidedAt
+			if !inRange(&decidedAt, filter.From, filter.To) {
+				continue
+			}
+
+			seconds := decidedAt.Sub(pr.reviewers[reviewerID]).Seconds()
+			key := reviewerID
+			if metric == repository.LatencyAssignmentToFirstDecisionByWeek {
+				key = aggregate.Week(decidedAt)
+			}
+			samples = append(samples, aggregate.Sample{Key: key, Seconds: seconds})
+		}
+	}
+
+	return aggregate.Latency(samples), nil
+}
+
+func (s *Store) GetReviewEdges(ctx context.Context, filter models.StatisticsFilter) ([]models.GraphEdge, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	type edgeKey struct{ author, reviewer string }
+	weights := make(map[edgeKey]int)
+	for _, pr := range s.prs {
+		if !s.matchesTeamStatistics(pr, filter) {
+			continue
+		}
+		for reviewerID := range pr.reviewers {
+			weights[edgeKey{pr.authorID, reviewerID}]++
+		}
+	}
+
+	var edges []models.GraphEdge
+	for key, weight := range weights {
+		edges = append(edges, models.GraphEdge{AuthorID: key.author, ReviewerID: key.reviewer, Weight: weight})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].AuthorID != edges[j].AuthorID {
+			return edges[i].AuthorID < edges[j].AuthorID
+		}
+		return edges[i].ReviewerID < edges[j].ReviewerID
+	})
+
+	return edges, nil
+}
+
+func (s *Store) GetPRAuthors(ctx context.Context, filter models.StatisticsFilter) ([]string, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var authors []string
+	for _, pr := range s.prs {
+		if !s.matchesTeamStatistics(pr, filter) || seen[pr.authorID] {
+			continue
+		}
+		seen[pr.authorID] = true
+		authors = append(authors, pr.authorID)
+	}
+	sort.Strings(authors)
+
+	return authors, nil
+}
+
+func (s *Store) GetGraphNodes(ctx context.Context, userIDs []string) ([]models.GraphNode, error) {
+	if len(userIDs) == 0 {
+		return []models.GraphNode{}, nil
+	}
+
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	wanted := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+
+	var nodes []models.GraphNode
+	for _, user := range s.sortedUsers() {
+		if wanted[user.UserID] {
+			nodes = append(nodes, models.GraphNode{
+				UserID:   user.UserID,
+				Username: user.Username,
+				TeamName: user.TeamName,
+			})
+		}
+	}
+
+	return nodes, nil
+}
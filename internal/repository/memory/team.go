@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+func (s *Store) CreateTeam(ctx context.Context, teamName string, members []models.TeamMember) error {
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+
+	s.teams[teamName] = true
+
+	now := s.now()
+	for _, member := range members {
+		if existing, ok := s.users[member.UserID]; ok {
+			existing.Username = member.Username
+			existing.TeamName = teamName
+			existing.IsActive = member.IsActive
+			continue
+		}
+
+		s.users[member.UserID] = &userRow{
+			User: models.User{
+				UserID:   member.UserID,
+				Username: member.Username,
+				TeamName: teamName,
+				IsActive: member.IsActive,
+			},
+			createdAt: now,
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) TeamExists(ctx context.Context, teamName string) (bool, error) {
+	if err := s.lock(ctx); err != nil {
+		return false, err
+	}
+	defer s.mu.Unlock()
+
+	return s.teams[teamName], nil
+}
+
+func (s *Store) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	if !s.teams[teamName] {
+		return nil, sql.ErrNoRows
+	}
+
+	team := models.Team{TeamName: teamName}
+	for _, user := range s.sortedUsers() {
+		if user.TeamName == teamName {
+			team.Members = append(team.Members, models.TeamMember{
+				UserID:   user.UserID,
+				Username: user.Username,
+				IsActive: user.IsActive,
+			})
+		}
+	}
+
+	return &team, nil
+}
+
+// sortedUsers returns all users ordered by user_id. The caller must hold mu.
+func (s *Store) sortedUsers() []*userRow {
+	users := make([]*userRow, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].UserID < users[j].UserID })
+	return users
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+func (s *Store) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	result := user.User
+	return &result, nil
+}
+
+func (s *Store) SetUserIsActive(ctx context.Context, userID string, isActive bool) error {
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	user.IsActive = isActive
+	return nil
+}
+
+func (s *Store) GetActiveUsersByTeamName(ctx context.Context, teamName string, excludeUserID string) ([]models.User, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	return s.activeTeammates(teamName, excludeUserID), nil
+}
+
+// activeTeammates returns the active members of teamName other than
+// excludeUserID, ordered by user_id. The caller must hold mu.
+func (s *Store) activeTeammates(teamName, excludeUserID string) []models.User {
+	var users []models.User
+	for _, user := range s.sortedUsers() {
+		if user.TeamName == teamName && user.IsActive && user.UserID != excludeUserID {
+			users = append(users, user.User)
+		}
+	}
+	return users
+}
+
+func (s *Store) GetUserReviewPRs(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
+	return s.userReviewPRs(ctx, userID, false)
+}
+
+func (s *Store) GetUserOpenReviewPRs(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
+	return s.userReviewPRs(ctx, userID, true)
+}
+
+func (s *Store) userReviewPRs(ctx context.Context, userID string, openOnly bool) ([]models.PullRequestShort, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	var prs []models.PullRequestShort
+	for _, pr := range s.sortedPRs(false) {
+		if _, ok := pr.reviewers[userID]; !ok {
+			continue
+		}
+		if openOnly && pr.status != "OPEN" {
+			continue
+		}
+		prs = append(prs, pr.short())
+	}
+
+	return prs, nil
+}
+
+func (s *Store) GetActiveMemberAssignments(ctx context.Context, from, to time.Time, teamName string) ([]models.MemberAssignments, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	var members []models.MemberAssignments
+	for _, user := range s.sortedUsers() {
+		if !user.IsActive || (teamName != "" && user.TeamName != teamName) {
+			continue
+		}
+
+		assignments := 0
+		for _, pr := range s.prs {
+			if assignedAt, ok := pr.reviewers[user.UserID]; ok && !assignedAt.Before(from) && assignedAt.Before(to) {
+				assignments++
+			}
+		}
+
+		members = append(members, models.MemberAssignments{
+			UserID:      user.UserID,
+			Username:    user.Username,
+			TeamName:    user.TeamName,
+			CreatedAt:   user.createdAt,
+			Assignments: assignments,
+		})
+	}
+
+	sort.SliceStable(members, func(i, j int) bool { return members[i].TeamName < members[j].TeamName })
+
+	return members, nil
+}
+
+func (s *Store) GetUserReviewStats(ctx context.Context, filter models.StatisticsFilter) ([]models.UserReviewStats, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	var stats []models.UserReviewStats
+	for _, user := range s.sortedUsers() {
+		if filter.TeamName != "" && user.TeamName != filter.TeamName {
+			continue
+		}
+
+		count := 0
+		for _, pr := range s.prs {
+			if _, ok := pr.reviewers[user.UserID]; ok && matchesPRStatistics(pr, filter) {
+				count++
+			}
+		}
+
+		stats = append(stats, models.UserReviewStats{
+			UserID:      user.UserID,
+			Username:    user.Username,
+			ReviewCount: count,
+		})
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].ReviewCount > stats[j].ReviewCount })
+
+	return stats, nil
+}
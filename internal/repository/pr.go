@@ -1,10 +1,15 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/jmoiron/sqlx"
+
 	"github.com/milyrock/PR-Reviewer/internal/models"
 )
 
@@ -12,31 +17,70 @@ const (
 	prExists = `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)`
 
 	selectPR = `
-		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
+		SELECT pull_request_id, pull_request_name, author_id, status, created_at, updated_at, merged_at
 		FROM pull_requests
 		WHERE pull_request_id = $1
 	`
 
-	selectPRReviewers = `
-		SELECT user_id
-		FROM pr_reviewers
+	selectPRForUpdate = `
+		SELECT pull_request_id, pull_request_name, author_id, status, created_at, updated_at, merged_at
+		FROM pull_requests
 		WHERE pull_request_id = $1
+		FOR UPDATE
+	`
+
+	selectAuthorForShare = `
+		SELECT user_id, username, team_name, is_active
+		FROM users
+		WHERE user_id = $1
+		FOR SHARE
+	`
+
+	selectCreateCandidates = `
+		SELECT user_id, username, team_name, is_active
+		FROM users
+		WHERE team_name = $1 AND is_active = true AND user_id != $2
 		ORDER BY user_id
+		FOR SHARE
+	`
+
+	selectReassignCandidates = `
+		SELECT u.user_id, u.username, u.team_name, u.is_active
+		FROM users u
+		INNER JOIN users old ON old.team_name = u.team_name
+		WHERE old.user_id = $1 AND u.is_active = true AND u.user_id != $1
+		ORDER BY u.user_id
+		FOR SHARE OF u
+	`
+
+	selectPRReviewers = `
+		SELECT prr.user_id, u.username, u.team_name, u.is_active, prr.assigned_at, d.decision, d.decided_at
+		FROM pr_reviewers prr
+		INNER JOIN users u ON prr.user_id = u.user_id
+		LEFT JOIN review_decisions d ON prr.pull_request_id = d.pull_request_id AND prr.user_id = d.user_id
+		WHERE prr.pull_request_id = $1
+		ORDER BY prr.user_id
 	`
 
 	insertPR = `
-		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
 	`
 
 	insertPRReviewer = `
-		INSERT INTO pr_reviewers (pull_request_id, user_id)
-		VALUES ($1, $2)
+		INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at)
+		VALUES ($1, $2, $3)
 	`
 
 	mergePR = `
 		UPDATE pull_requests
-		SET status = 'MERGED', merged_at = $1
+		SET status = 'MERGED', merged_at = $1, updated_at = $1
+		WHERE pull_request_id = $2 AND status = 'OPEN'
+	`
+
+	touchPR = `
+		UPDATE pull_requests
+		SET updated_at = $1
 		WHERE pull_request_id = $2
 	`
 
@@ -47,111 +91,297 @@ const (
 		)
 	`
 
+	// upsertReviewDecision records a decision only for a reviewer assigned to
+	// an open PR. A changed decision keeps first_decided_at.
+	upsertReviewDecision = `
+		INSERT INTO review_decisions (pull_request_id, user_id, decision, decided_at, first_decided_at)
+		SELECT prr.pull_request_id, prr.user_id, $3::varchar, $4::timestamptz, $4::timestamptz
+		FROM pr_reviewers prr
+		INNER JOIN pull_requests pr ON prr.pull_request_id = pr.pull_request_id
+		WHERE prr.pull_request_id = $1 AND prr.user_id = $2 AND pr.status = 'OPEN'
+		ON CONFLICT (pull_request_id, user_id) DO UPDATE
+		SET decision = EXCLUDED.decision, decided_at = EXCLUDED.decided_at
+	`
+
 	deletePRReviewer = `
 		DELETE FROM pr_reviewers
 		WHERE pull_request_id = $1 AND user_id = $2
 	`
 
+	selectPRList = `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.updated_at, pr.merged_at
+		FROM pull_requests pr
+		INNER JOIN users u ON pr.author_id = u.user_id
+	`
+
+	selectReviewersForPRs = `
+		SELECT prr.pull_request_id, prr.user_id, u.username, u.team_name, u.is_active, prr.assigned_at, d.decision, d.decided_at
+		FROM pr_reviewers prr
+		INNER JOIN users u ON prr.user_id = u.user_id
+		LEFT JOIN review_decisions d ON prr.pull_request_id = d.pull_request_id AND prr.user_id = d.user_id
+		WHERE prr.pull_request_id IN (?)
+		ORDER BY prr.pull_request_id, prr.user_id
+	`
+
+	selectUserRecentlyMergedPRs = `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.updated_at, pr.merged_at
+		FROM pull_requests pr
+		WHERE pr.status = 'MERGED' AND pr.merged_at >= $2
+			AND (pr.author_id = $1 OR EXISTS(
+				SELECT 1 FROM pr_reviewers prr
+				WHERE prr.pull_request_id = pr.pull_request_id AND prr.user_id = $1
+			))
+		ORDER BY pr.merged_at DESC
+		LIMIT $3
+	`
+
+	selectPRExport = `
+		SELECT
+			pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status,
+			pr.created_at, pr.updated_at, pr.merged_at,
+			COALESCE(
+				json_agg(json_build_object(
+					'user_id', u.user_id,
+					'username', u.username,
+					'team_name', u.team_name,
+					'is_active', u.is_active,
+					'assigned_at', prr.assigned_at,
+					'decision', d.decision,
+					'decided_at', d.decided_at
+				) ORDER BY prr.user_id) FILTER (WHERE prr.user_id IS NOT NULL),
+				'[]'
+			) AS reviewers_json
+		FROM pull_requests pr
+		LEFT JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		LEFT JOIN users u ON prr.user_id = u.user_id
+		LEFT JOIN review_decisions d ON prr.pull_request_id = d.pull_request_id AND prr.user_id = d.user_id
+		GROUP BY pr.pull_request_id
+		ORDER BY pr.created_at, pr.pull_request_id
+	`
+
+	selectOpenPRCountsByTeam = `
+		SELECT u.team_name, COUNT(*) AS open_count
+		FROM pull_requests pr
+		INNER JOIN users u ON pr.author_id = u.user_id
+		WHERE pr.status = 'OPEN'
+		GROUP BY u.team_name
+	`
+
 	selectPRReviewStats = `
 		SELECT 
 			pr.pull_request_id,
 			pr.pull_request_name,
 			COUNT(prr.user_id) as reviewer_count
 		FROM pull_requests pr
+		INNER JOIN users a ON pr.author_id = a.user_id
 		LEFT JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		%s
 		GROUP BY pr.pull_request_id, pr.pull_request_name
 		ORDER BY pr.created_at DESC
 	`
 )
 
-func (r *Repository) PRExists(pullRequestID string) (bool, error) {
+func (r *Repository) PRExists(ctx context.Context, pullRequestID string) (bool, error) {
 	var exists bool
-	err := r.db.Get(&exists, prExists, pullRequestID)
+	err := r.db.GetContext(ctx, &exists, prExists, pullRequestID)
 	return exists, err
 }
 
-func (r *Repository) IsReviewerAssigned(pullRequestID, userID string) (bool, error) {
+func (r *Repository) IsReviewerAssigned(ctx context.Context, pullRequestID, userID string) (bool, error) {
 	var exists bool
-	err := r.db.Get(&exists, reviewerAssigned, pullRequestID, userID)
+	err := r.db.GetContext(ctx, &exists, reviewerAssigned, pullRequestID, userID)
 	return exists, err
 }
 
-func (r *Repository) GetPR(pullRequestID string) (*models.PullRequest, error) {
+func (r *Repository) GetPR(ctx context.Context, pullRequestID string) (*models.PullRequest, error) {
 	var pr models.PullRequest
-	err := r.db.Get(&pr, selectPR, pullRequestID)
+	err := r.db.GetContext(ctx, &pr, selectPR, pullRequestID)
 	if err != nil {
 		return nil, err
 	}
 
-	err = r.db.Select(&pr.AssignedReviewers, selectPRReviewers, pullRequestID)
+	err = r.db.SelectContext(ctx, &pr.Reviewers, selectPRReviewers, pullRequestID)
 	if err != nil {
 		return nil, err
 	}
 
+	pr.AssignedReviewers = make([]string, 0, len(pr.Reviewers))
+	for _, reviewer := range pr.Reviewers {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, reviewer.UserID)
+	}
+
 	return &pr, nil
 }
 
-func (r *Repository) CreatePR(pr *models.PullRequest, reviewerIDs []string) error {
-	tx, err := r.db.Beginx()
+// CreateChooser picks the reviewers for a new PR from the active teammates of
+// its author. Returning an error aborts the insert.
+type CreateChooser func(author *models.User, candidates []models.User) ([]string, error)
+
+// CreatePR share-locks the author and the candidate reviewers, lets choose pick
+// the reviewers and inserts the PR with them in one transaction, so a user
+// deactivated concurrently is never assigned. A missing author surfaces as
+// sql.ErrNoRows and an existing PR id as ErrDuplicate. The chosen reviewers
+// are stored in pr.AssignedReviewers.
+func (r *Repository) CreatePR(ctx context.Context, pr *models.PullRequest, choose CreateChooser) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	defer tx.Rollback() //nolint:errcheck
+	defer Rollback(ctx, tx)
+
+	var author models.User
+	if err := tx.GetContext(ctx, &author, selectAuthorForShare, pr.AuthorID); err != nil {
+		return err
+	}
+
+	var candidates []models.User
+	if err := tx.SelectContext(ctx, &candidates, selectCreateCandidates, author.TeamName, author.UserID); err != nil {
+		return err
+	}
+
+	reviewerIDs, err := choose(&author, candidates)
+	if err != nil {
+		return err
+	}
+	pr.AssignedReviewers = reviewerIDs
 
 	now := time.Now()
-	_, err = tx.Exec(insertPR, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, now)
+	_, err = tx.ExecContext(ctx, insertPR, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, now)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
 		return err
 	}
 
 	for _, reviewerID := range reviewerIDs {
-		_, err = tx.Exec(insertPRReviewer, pr.PullRequestID, reviewerID)
+		_, err = tx.ExecContext(ctx, insertPRReviewer, pr.PullRequestID, reviewerID, now)
 		if err != nil {
 			return err
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		return err
+	}
+
+	return nil
 }
 
-func (r *Repository) MergePR(pullRequestID string) error {
-	pr, err := r.GetPR(pullRequestID)
+// MergePR marks an open PR as merged. Merging an already merged PR is a no-op,
+// so concurrent merges keep the first merged_at.
+func (r *Repository) MergePR(ctx context.Context, pullRequestID string) error {
+	result, err := r.db.ExecContext(ctx, mergePR, time.Now(), pullRequestID)
 	if err != nil {
 		return err
 	}
 
-	if pr.Status == "MERGED" {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected > 0 {
 		return nil
 	}
 
-	now := time.Now()
-	result, err := r.db.Exec(mergePR, now, pullRequestID)
+	exists, err := r.PRExists(ctx, pullRequestID)
 	if err != nil {
 		return err
 	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ReassignChooser picks the replacement reviewer for a locked PR from the
+// active members of the old reviewer's team. Returning an error aborts the
+// reassignment.
+type ReassignChooser func(pr *models.PullRequest, candidates []models.User) (string, error)
+
+// ReassignReviewer locks the PR row, lets choose pick the replacement from the
+// state read under the lock and swaps the reviewers in the same transaction,
+// so concurrent reassignments of one PR are serialised. It returns the id of
+// the new reviewer, or ErrNotAssigned if oldUserID is not a reviewer of the PR.
+func (r *Repository) ReassignReviewer(ctx context.Context, pullRequestID, oldUserID string, choose ReassignChooser) (string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+
+	defer Rollback(ctx, tx)
+
+	var pr models.PullRequest
+	if err := tx.GetContext(ctx, &pr, selectPRForUpdate, pullRequestID); err != nil {
+		return "", err
+	}
+
+	if err := tx.SelectContext(ctx, &pr.Reviewers, selectPRReviewers, pullRequestID); err != nil {
+		return "", err
+	}
+
+	pr.AssignedReviewers = make([]string, 0, len(pr.Reviewers))
+	for _, reviewer := range pr.Reviewers {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, reviewer.UserID)
+	}
+
+	var candidates []models.User
+	if err := tx.SelectContext(ctx, &candidates, selectReassignCandidates, oldUserID); err != nil {
+		return "", err
+	}
+
+	newUserID, err := choose(&pr, candidates)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := tx.ExecContext(ctx, deletePRReviewer, pullRequestID, oldUserID)
+	if err != nil {
+		return "", err
+	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if rowsAffected == 0 {
-		return sql.ErrNoRows
+		return "", ErrNotAssigned
 	}
 
-	return nil
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, insertPRReviewer, pullRequestID, newUserID, now)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.ExecContext(ctx, touchPR, now, pullRequestID)
+	if err != nil {
+		return "", err
+	}
+
+	return newUserID, tx.Commit()
 }
 
-func (r *Repository) ReassignReviewer(pullRequestID, oldUserID, newUserID string) error {
-	tx, err := r.db.Beginx()
+// RecordReviewDecision stores the decision of a reviewer assigned to an open
+// PR and touches the PR. It returns sql.ErrNoRows when the PR is missing or
+// merged, or the user is not one of its reviewers.
+func (r *Repository) RecordReviewDecision(ctx context.Context, pullRequestID, userID, decision string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	defer tx.Rollback() //nolint:errcheck
+	defer Rollback(ctx, tx)
 
-	result, err := tx.Exec(deletePRReviewer, pullRequestID, oldUserID)
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, upsertReviewDecision, pullRequestID, userID, decision, now)
 	if err != nil {
 		return err
 	}
@@ -160,21 +390,205 @@ func (r *Repository) ReassignReviewer(pullRequestID, oldUserID, newUserID string
 	if err != nil {
 		return err
 	}
-
 	if rowsAffected == 0 {
-		return fmt.Errorf("reviewer not assigned")
+		return sql.ErrNoRows
 	}
 
-	_, err = tx.Exec(insertPRReviewer, pullRequestID, newUserID)
-	if err != nil {
+	if _, err := tx.ExecContext(ctx, touchPR, now, pullRequestID); err != nil {
 		return err
 	}
 
 	return tx.Commit()
 }
 
-func (r *Repository) GetPRReviewStats() ([]models.PRReviewStats, error) {
+func (r *Repository) GetPRReviewStats(ctx context.Context, filter models.StatisticsFilter) ([]models.PRReviewStats, error) {
+	conds, args := prStatisticsConds(filter)
+	if filter.TeamName != "" {
+		conds = append(conds, "a.team_name = ?")
+		args = append(args, filter.TeamName)
+	}
+
+	query := fmt.Sprintf(selectPRReviewStats, whereClause(conds))
+
 	var stats []models.PRReviewStats
-	err := r.db.Select(&stats, selectPRReviewStats)
+	err := r.db.SelectContext(ctx, &stats, r.db.Rebind(query), args...)
 	return stats, err
 }
+
+func (r *Repository) ListPRs(ctx context.Context, filter models.PRListFilter) ([]models.PullRequest, error) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	if filter.Status != "" {
+		conds = append(conds, "pr.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.AuthorID != "" {
+		conds = append(conds, "pr.author_id = ?")
+		args = append(args, filter.AuthorID)
+	}
+	if filter.ReviewerID != "" {
+		conds = append(conds, "EXISTS(SELECT 1 FROM pr_reviewers prr WHERE prr.pull_request_id = pr.pull_request_id AND prr.user_id = ?)")
+		args = append(args, filter.ReviewerID)
+	}
+	if filter.TeamName != "" {
+		conds = append(conds, "u.team_name = ?")
+		args = append(args, filter.TeamName)
+	}
+	if filter.Name != "" {
+		conds = append(conds, "pr.pull_request_name ILIKE ?")
+		args = append(args, "%"+escapeLike(filter.Name)+"%")
+	}
+	if filter.CreatedFrom != nil {
+		conds = append(conds, "pr.created_at >= ?")
+		args = append(args, *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		conds = append(conds, "pr.created_at < ?")
+		args = append(args, *filter.CreatedTo)
+	}
+	if filter.MergedFrom != nil {
+		conds = append(conds, "pr.merged_at >= ?")
+		args = append(args, *filter.MergedFrom)
+	}
+	if filter.MergedTo != nil {
+		conds = append(conds, "pr.merged_at < ?")
+		args = append(args, *filter.MergedTo)
+	}
+
+	order, cmp := "DESC", "<"
+	if filter.SortAsc {
+		order, cmp = "ASC", ">"
+	}
+
+	if filter.After != nil {
+		conds = append(conds, fmt.Sprintf("(pr.created_at, pr.pull_request_id) %s (?, ?)", cmp))
+		args = append(args, filter.After.CreatedAt, filter.After.PullRequestID)
+	}
+
+	query := selectPRList + whereClause(conds)
+	query += fmt.Sprintf(" ORDER BY pr.created_at %s, pr.pull_request_id %s LIMIT ?", order, order)
+	args = append(args, filter.Limit)
+
+	var prs []models.PullRequest
+	if err := r.db.SelectContext(ctx, &prs, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	if err := r.fillReviewers(ctx, prs); err != nil {
+		return nil, err
+	}
+
+	return prs, nil
+}
+
+func (r *Repository) fillReviewers(ctx context.Context, prs []models.PullRequest) error {
+	if len(prs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		ids = append(ids, pr.PullRequestID)
+	}
+
+	query, args, err := sqlx.In(selectReviewersForPRs, ids)
+	if err != nil {
+		return err
+	}
+
+	var rows []struct {
+		PullRequestID string `db:"pull_request_id"`
+		models.PRReviewer
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	reviewers := make(map[string][]models.PRReviewer, len(prs))
+	for _, row := range rows {
+		reviewers[row.PullRequestID] = append(reviewers[row.PullRequestID], row.PRReviewer)
+	}
+
+	for i := range prs {
+		prs[i].Reviewers = reviewers[prs[i].PullRequestID]
+		prs[i].AssignedReviewers = make([]string, 0, len(prs[i].Reviewers))
+		for _, reviewer := range prs[i].Reviewers {
+			prs[i].AssignedReviewers = append(prs[i].AssignedReviewers, reviewer.UserID)
+		}
+	}
+
+	return nil
+}
+
+func (r *Repository) GetUserRecentlyMergedPRs(ctx context.Context, userID string, since time.Time, limit int) ([]models.PullRequest, error) {
+	var prs []models.PullRequest
+	if err := r.db.SelectContext(ctx, &prs, selectUserRecentlyMergedPRs, userID, since, limit); err != nil {
+		return nil, err
+	}
+
+	if err := r.fillReviewers(ctx, prs); err != nil {
+		return nil, err
+	}
+
+	return prs, nil
+}
+
+// StreamPRs calls fn for every pull request, reading rows one at a time so the
+// full table is never held in memory.
+func (r *Repository) StreamPRs(ctx context.Context, fn func(*models.PullRequest) error) error {
+	rows, err := r.db.QueryxContext(ctx, selectPRExport)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row struct {
+			models.PullRequest
+			ReviewersJSON []byte `db:"reviewers_json"`
+		}
+		if err := rows.StructScan(&row); err != nil {
+			return err
+		}
+
+		pr := row.PullRequest
+		if err := json.Unmarshal(row.ReviewersJSON, &pr.Reviewers); err != nil {
+			return err
+		}
+
+		pr.AssignedReviewers = make([]string, 0, len(pr.Reviewers))
+		for _, reviewer := range pr.Reviewers {
+			pr.AssignedReviewers = append(pr.AssignedReviewers, reviewer.UserID)
+		}
+
+		if err := fn(&pr); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *Repository) CountOpenPRsByTeam(ctx context.Context) (map[string]int, error) {
+	var rows []struct {
+		TeamName  string `db:"team_name"`
+		OpenCount int    `db:"open_count"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, selectOpenPRCountsByTeam); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.TeamName] = row.OpenCount
+	}
+
+	return counts, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository"
+)
+
+const (
+	insertAPIKey = `
+		INSERT INTO api_keys (key_id, name, key_hash, role, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	selectAPIKeyByHash = `
+		SELECT key_id, name, role, created_at, revoked_at
+		FROM api_keys
+		WHERE key_hash = ? AND revoked_at IS NULL
+	`
+
+	selectAPIKeys = `
+		SELECT key_id, name, role, created_at, revoked_at
+		FROM api_keys
+		ORDER BY created_at, key_id
+	`
+
+	revokeAPIKey = `
+		UPDATE api_keys
+		SET revoked_at = ?
+		WHERE key_id = ? AND revoked_at IS NULL
+	`
+)
+
+func (s *Store) CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	_, err := s.db.ExecContext(ctx, insertAPIKey, key.KeyID, key.Name, keyHash, key.Role, utc(key.CreatedAt))
+	if isUniqueViolation(err) {
+		return repository.ErrDuplicate
+	}
+	return err
+}
+
+func (s *Store) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := s.db.GetContext(ctx, &key, selectAPIKeyByHash, keyHash); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (s *Store) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := s.db.SelectContext(ctx, &keys, selectAPIKeys)
+	return keys, err
+}
+
+func (s *Store) RevokeAPIKey(ctx context.Context, keyID string) error {
+	result, err := s.db.ExecContext(ctx, revokeAPIKey, now(), keyID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
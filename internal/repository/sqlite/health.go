@@ -0,0 +1,23 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+// SchemaVersion is the version db/sqlite.sql records in schema_version. Bump
+// both together.
+const SchemaVersion = 3
+
+const selectSchemaVersion = `SELECT COALESCE(MAX(version), 0) FROM schema_version`
+
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+func (s *Store) SchemaVersion(ctx context.Context) (models.SchemaVersion, error) {
+	version := models.SchemaVersion{Expected: SchemaVersion}
+	err := s.db.GetContext(ctx, &version.Current, selectSchemaVersion)
+	return version, err
+}
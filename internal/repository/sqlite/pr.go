@@ -0,0 +1,578 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository"
+)
+
+const (
+	prExists = `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = ?)`
+
+	selectPR = `
+		SELECT pull_request_id, pull_request_name, author_id, status, created_at, updated_at, merged_at
+		FROM pull_requests
+		WHERE pull_request_id = ?
+	`
+
+	selectReassignCandidates = `
+		SELECT u.user_id, u.username, u.team_name, u.is_active
+		FROM users u
+		INNER JOIN users old ON old.team_name = u.team_name
+		WHERE old.user_id = ?1 AND u.is_active = true AND u.user_id != ?1
+		ORDER BY u.user_id
+	`
+
+	selectPRReviewers = `
+		SELECT prr.user_id, u.username, u.team_name, u.is_active, prr.assigned_at, d.decision, d.decided_at
+		FROM pr_reviewers prr
+		INNER JOIN users u ON prr.user_id = u.user_id
+		LEFT JOIN review_decisions d ON prr.pull_request_id = d.pull_request_id AND prr.user_id = d.user_id
+		WHERE prr.pull_request_id = ?
+		ORDER BY prr.user_id
+	`
+
+	insertPR = `
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?5)
+	`
+
+	insertPRReviewer = `
+		INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at)
+		VALUES (?, ?, ?)
+	`
+
+	mergePR = `
+		UPDATE pull_requests
+		SET status = 'MERGED', merged_at = ?1, updated_at = ?1
+		WHERE pull_request_id = ?2 AND status = 'OPEN'
+	`
+
+	touchPR = `
+		UPDATE pull_requests
+		SET updated_at = ?
+		WHERE pull_request_id = ?
+	`
+
+	// upsertReviewDecision records a decision only for a reviewer assigned to
+	// an open PR. A changed decision keeps first_decided_at.
+	upsertReviewDecision = `
+		INSERT INTO review_decisions (pull_request_id, user_id, decision, decided_at, first_decided_at)
+		SELECT prr.pull_request_id, prr.user_id, ?3, ?4, ?4
+		FROM pr_reviewers prr
+		INNER JOIN pull_requests pr ON prr.pull_request_id = pr.pull_request_id
+		WHERE prr.pull_request_id = ?1 AND prr.user_id = ?2 AND pr.status = 'OPEN'
+		ON CONFLICT (pull_request_id, user_id) DO UPDATE
+		SET decision = excluded.decision, decided_at = excluded.decided_at
+	`
+
+	deletePRReviewer = `
+		DELETE FROM pr_reviewers
+		WHERE pull_request_id = ? AND user_id = ?
+	`
+
+	selectPRList = `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.updated_at, pr.merged_at
+		FROM pull_requests pr
+		INNER JOIN users u ON pr.author_id = u.user_id
+	`
+
+	selectReviewersForPRs = `
+		SELECT prr.pull_request_id, prr.user_id, u.username, u.team_name, u.is_active, prr.assigned_at, d.decision, d.decided_at
+		FROM pr_reviewers prr
+		INNER JOIN users u ON prr.user_id = u.user_id
+		LEFT JOIN review_decisions d ON prr.pull_request_id = d.pull_request_id AND prr.user_id = d.user_id
+		WHERE prr.pull_request_id IN (?)
+		ORDER BY prr.pull_request_id, prr.user_id
+	`
+
+	selectUserRecentlyMergedPRs = `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.updated_at, pr.merged_at
+		FROM pull_requests pr
+		WHERE pr.status = 'MERGED' AND pr.merged_at >= ?2
+			AND (pr.author_id = ?1 OR EXISTS(
+				SELECT 1 FROM pr_reviewers prr
+				WHERE prr.pull_request_id = pr.pull_request_id AND prr.user_id = ?1
+			))
+		ORDER BY pr.merged_at DESC
+		LIMIT ?3
+	`
+
+	// selectPRExport joins every PR with its reviewers ordered so that the rows
+	// of one PR are adjacent; StreamPRs folds them back together.
+	selectPRExport = `
+		SELECT
+			pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status,
+			pr.created_at, pr.updated_at, pr.merged_at,
+			prr.user_id AS reviewer_id,
+			u.username AS reviewer_username,
+			u.team_name AS reviewer_team_name,
+			u.is_active AS reviewer_is_active,
+			prr.assigned_at AS reviewer_assigned_at,
+			d.decision AS reviewer_decision,
+			d.decided_at AS reviewer_decided_at
+		FROM pull_requests pr
+		LEFT JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		LEFT JOIN users u ON prr.user_id = u.user_id
+		LEFT JOIN review_decisions d ON prr.pull_request_id = d.pull_request_id AND prr.user_id = d.user_id
+		ORDER BY pr.created_at, pr.pull_request_id, prr.user_id
+	`
+
+	selectOpenPRCountsByTeam = `
+		SELECT u.team_name, COUNT(*) AS open_count
+		FROM pull_requests pr
+		INNER JOIN users u ON pr.author_id = u.user_id
+		WHERE pr.status = 'OPEN'
+		GROUP BY u.team_name
+	`
+
+	selectPRReviewStats = `
+		SELECT
+			pr.pull_request_id,
+			pr.pull_request_name,
+			COUNT(prr.user_id) AS reviewer_count
+		FROM pull_requests pr
+		INNER JOIN users a ON pr.author_id = a.user_id
+		LEFT JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		%s
+		GROUP BY pr.pull_request_id, pr.pull_request_name
+		ORDER BY pr.created_at DESC
+	`
+)
+
+func (s *Store) PRExists(ctx context.Context, pullRequestID string) (bool, error) {
+	var exists bool
+	err := s.db.GetContext(ctx, &exists, prExists, pullRequestID)
+	return exists, err
+}
+
+func (s *Store) GetPR(ctx context.Context, pullRequestID string) (*models.PullRequest, error) {
+	var pr models.PullRequest
+	err := s.db.GetContext(ctx, &pr, selectPR, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.SelectContext(ctx, &pr.Reviewers, selectPRReviewers, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	pr.AssignedReviewers = make([]string, 0, len(pr.Reviewers))
+	for _, reviewer := range pr.Reviewers {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, reviewer.UserID)
+	}
+
+	return &pr, nil
+}
+
+// CreatePR reads the author and the candidate reviewers, lets choose pick the
+// reviewers and inserts the PR with them in one transaction. Transactions
+// begin IMMEDIATE, so no user can be deactivated between the read and the
+// insert. A missing author surfaces as sql.ErrNoRows and an existing PR id as
+// repository.ErrDuplicate.
+func (s *Store) CreatePR(ctx context.Context, pr *models.PullRequest, choose repository.CreateChooser) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer repository.Rollback(ctx, tx)
+
+	var author models.User
+	if err := tx.GetContext(ctx, &author, selectUser, pr.AuthorID); err != nil {
+		return err
+	}
+
+	var candidates []models.User
+	if err := tx.SelectContext(ctx, &candidates, selectActiveUsersByTeam, author.TeamName, author.UserID); err != nil {
+		return err
+	}
+
+	reviewerIDs, err := choose(&author, candidates)
+	if err != nil {
+		return err
+	}
+	pr.AssignedReviewers = reviewerIDs
+
+	createdAt := now()
+	_, err = tx.ExecContext(ctx, insertPR, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, createdAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repository.ErrDuplicate
+		}
+		return err
+	}
+
+	for _, reviewerID := range reviewerIDs {
+		_, err = tx.ExecContext(ctx, insertPRReviewer, pr.PullRequestID, reviewerID, createdAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// MergePR marks an open PR as merged. Merging an already merged PR is a no-op
+// and keeps the first merged_at.
+func (s *Store) MergePR(ctx context.Context, pullRequestID string) error {
+	result, err := s.db.ExecContext(ctx, mergePR, now(), pullRequestID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	exists, err := s.PRExists(ctx, pullRequestID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ReassignReviewer reads the PR, lets choose pick the replacement and swaps the
+// reviewers in one transaction. Transactions begin IMMEDIATE, so the write
+// lock is held from the first read and concurrent reassignments are
+// serialised.
+func (s *Store) ReassignReviewer(ctx context.Context, pullRequestID, oldUserID string, choose repository.ReassignChooser) (string, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+
+	defer repository.Rollback(ctx, tx)
+
+	var pr models.PullRequest
+	if err := tx.GetContext(ctx, &pr, selectPR, pullRequestID); err != nil {
+		return "", err
+	}
+
+	if err := tx.SelectContext(ctx, &pr.Reviewers, selectPRReviewers, pullRequestID); err != nil {
+		return "", err
+	}
+
+	pr.AssignedReviewers = make([]string, 0, len(pr.Reviewers))
+	for _, reviewer := range pr.Reviewers {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, reviewer.UserID)
+	}
+
+	var candidates []models.User
+	if err := tx.SelectContext(ctx, &candidates, selectReassignCandidates, oldUserID); err != nil {
+		return "", err
+	}
+
+	newUserID, err := choose(&pr, candidates)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := tx.ExecContext(ctx, deletePRReviewer, pullRequestID, oldUserID)
+	if err != nil {
+		return "", err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return "", err
+	}
+
+	if rowsAffected == 0 {
+		return "", repository.ErrNotAssigned
+	}
+
+	assignedAt := now()
+	_, err = tx.ExecContext(ctx, insertPRReviewer, pullRequestID, newUserID, assignedAt)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.ExecContext(ctx, touchPR, assignedAt, pullRequestID)
+	if err != nil {
+		return "", err
+	}
+
+	return newUserID, tx.Commit()
+}
+
+// RecordReviewDecision stores the decision of a reviewer assigned to an open
+// PR and touches the PR. It returns sql.ErrNoRows when the PR is missing or
+// merged, or the user is not one of its reviewers.
+func (s *Store) RecordReviewDecision(ctx context.Context, pullRequestID, userID, decision string) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer repository.Rollback(ctx, tx)
+
+	decidedAt := now()
+	result, err := tx.ExecContext(ctx, upsertReviewDecision, pullRequestID, userID, decision, decidedAt)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, touchPR, decidedAt, pullRequestID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) GetPRReviewStats(ctx context.Context, filter models.StatisticsFilter) ([]models.PRReviewStats, error) {
+	conds, args := prStatisticsConds(filter)
+	if filter.TeamName != "" {
+		conds = append(conds, "a.team_name = ?")
+		args = append(args, filter.TeamName)
+	}
+
+	query := fmt.Sprintf(selectPRReviewStats, whereClause(conds))
+
+	var stats []models.PRReviewStats
+	err := s.db.SelectContext(ctx, &stats, query, args...)
+	return stats, err
+}
+
+func (s *Store) ListPRs(ctx context.Context, filter models.PRListFilter) ([]models.PullRequest, error) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	if filter.Status != "" {
+		conds = append(conds, "pr.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.AuthorID != "" {
+		conds = append(conds, "pr.author_id = ?")
+		args = append(args, filter.AuthorID)
+	}
+	if filter.ReviewerID != "" {
+		conds = append(conds, "EXISTS(SELECT 1 FROM pr_reviewers prr WHERE prr.pull_request_id = pr.pull_request_id AND prr.user_id = ?)")
+		args = append(args, filter.ReviewerID)
+	}
+	if filter.TeamName != "" {
+		conds = append(conds, "u.team_name = ?")
+		args = append(args, filter.TeamName)
+	}
+	if filter.Name != "" {
+		// LIKE is case-insensitive for ASCII in SQLite, like ILIKE.
+		conds = append(conds, `pr.pull_request_name LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(filter.Name)+"%")
+	}
+	if filter.CreatedFrom != nil {
+		conds = append(conds, "pr.created_at >= ?")
+		args = append(args, utc(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conds = append(conds, "pr.created_at < ?")
+		args = append(args, utc(*filter.CreatedTo))
+	}
+	if filter.MergedFrom != nil {
+		conds = append(conds, "pr.merged_at >= ?")
+		args = append(args, utc(*filter.MergedFrom))
+	}
+	if filter.MergedTo != nil {
+		conds = append(conds, "pr.merged_at < ?")
+		args = append(args, utc(*filter.MergedTo))
+	}
+
+	order, cmp := "DESC", "<"
+	if filter.SortAsc {
+		order, cmp = "ASC", ">"
+	}
+
+	if filter.After != nil {
+		conds = append(conds, fmt.Sprintf("(pr.created_at, pr.pull_request_id) %s (?, ?)", cmp))
+		args = append(args, utc(filter.After.CreatedAt), filter.After.PullRequestID)
+	}
+
+	query := selectPRList + whereClause(conds)
+	query += fmt.Sprintf(" ORDER BY pr.created_at %s, pr.pull_request_id %s LIMIT ?", order, order)
+	args = append(args, filter.Limit)
+
+	var prs []models.PullRequest
+	if err := s.db.SelectContext(ctx, &prs, query, args...); err != nil {
+		return nil, err
+	}
+
+	if err := s.fillReviewers(ctx, prs); err != nil {
+		return nil, err
+	}
+
+	return prs, nil
+}
+
+func (s *Store) fillReviewers(ctx context.Context, prs []models.PullRequest) error {
+	if len(prs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		ids = append(ids, pr.PullRequestID)
+	}
+
+	query, args, err := sqlx.In(selectReviewersForPRs, ids)
+	if err != nil {
+		return err
+	}
+
+	var rows []struct {
+		PullRequestID string `db:"pull_request_id"`
+		models.PRReviewer
+	}
+	if err := s.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return err
+	}
+
+	reviewers := make(map[string][]models.PRReviewer, len(prs))
+	for _, row := range rows {
+		reviewers[row.PullRequestID] = append(reviewers[row.PullRequestID], row.PRReviewer)
+	}
+
+	for i := range prs {
+		prs[i].Reviewers = reviewers[prs[i].PullRequestID]
+		prs[i].AssignedReviewers = make([]string, 0, len(prs[i].Reviewers))
+		for _, reviewer := range prs[i].Reviewers {
+			prs[i].AssignedReviewers = append(prs[i].AssignedReviewers, reviewer.UserID)
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) GetUserRecentlyMergedPRs(ctx context.Context, userID string, since time.Time, limit int) ([]models.PullRequest, error) {
+	var prs []models.PullRequest
+	if err := s.db.SelectContext(ctx, &prs, selectUserRecentlyMergedPRs, userID, utc(since), limit); err != nil {
+		return nil, err
+	}
+
+	if err := s.fillReviewers(ctx, prs); err != nil {
+		return nil, err
+	}
+
+	return prs, nil
+}
+
+type exportRow struct {
+	models.PullRequest
+	ReviewerID         sql.NullString `db:"reviewer_id"`
+	ReviewerUsername   sql.NullString `db:"reviewer_username"`
+	ReviewerTeamName   sql.NullString `db:"reviewer_team_name"`
+	ReviewerIsActive   sql.NullBool   `db:"reviewer_is_active"`
+	ReviewerAssignedAt sql.NullTime   `db:"reviewer_assigned_at"`
+	ReviewerDecision   sql.NullString `db:"reviewer_decision"`
+	ReviewerDecidedAt  sql.NullTime   `db:"reviewer_decided_at"`
+}
+
+// StreamPRs calls fn for every pull request, reading rows one at a time so the
+// full table is never held in memory.
+func (s *Store) StreamPRs(ctx context.Context, fn func(*models.PullRequest) error) error {
+	rows, err := s.db.QueryxContext(ctx, selectPRExport)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var current *models.PullRequest
+	emit := func() error {
+		if current == nil {
+			return nil
+		}
+
+		current.AssignedReviewers = make([]string, 0, len(current.Reviewers))
+		for _, reviewer := range current.Reviewers {
+			current.AssignedReviewers = append(current.AssignedReviewers, reviewer.UserID)
+		}
+
+		pr := current
+		current = nil
+		return fn(pr)
+	}
+
+	for rows.Next() {
+		var row exportRow
+		if err := rows.StructScan(&row); err != nil {
+			return err
+		}
+
+		if current == nil || current.PullRequestID != row.PullRequestID {
+			if err := emit(); err != nil {
+				return err
+			}
+
+			pr := row.PullRequest
+			pr.Reviewers = []models.PRReviewer{}
+			current = &pr
+		}
+
+		if row.ReviewerID.Valid {
+			reviewer := models.PRReviewer{
+				UserID:     row.ReviewerID.String,
+				Username:   row.ReviewerUsername.String,
+				TeamName:   row.ReviewerTeamName.String,
+				IsActive:   row.ReviewerIsActive.Bool,
+				AssignedAt: row.ReviewerAssignedAt.Time,
+			}
+			if row.ReviewerDecision.Valid {
+				reviewer.Decision = &row.ReviewerDecision.String
+				reviewer.DecidedAt = &row.ReviewerDecidedAt.Time
+			}
+			current.Reviewers = append(current.Reviewers, reviewer)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return emit()
+}
+
+func (s *Store) CountOpenPRsByTeam(ctx context.Context) (map[string]int, error) {
+	var rows []struct {
+		TeamName  string `db:"team_name"`
+		OpenCount int    `db:"open_count"`
+	}
+	if err := s.db.SelectContext(ctx, &rows, selectOpenPRCountsByTeam); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.TeamName] = row.OpenCount
+	}
+
+	return counts, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
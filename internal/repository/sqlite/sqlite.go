@@ -0,0 +1,80 @@
+// Package sqlite implements service.Store on top of an embedded SQLite
+// database for single-node deployments. It keeps the semantics of the
+// Postgres repository: the same ordering, uniqueness errors and transactional
+// reassignment. Statistics that Postgres computes with percentile_cont and
+// date_trunc are aggregated in Go.
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Store is the SQLite counterpart of repository.Repository.
+type Store struct {
+	db *sqlx.DB
+}
+
+func NewStore(db *sqlx.DB) *Store {
+	return &Store{db: db}
+}
+
+// Open opens the database file at path. Foreign keys are enforced, the
+// journal runs in WAL mode so readers do not block the writer, and every
+// transaction takes the write lock up front (BEGIN IMMEDIATE), which stands in
+// for SELECT ... FOR UPDATE. Times are written in a sortable text format so
+// range conditions can compare them directly.
+func Open(path string) (*sqlx.DB, error) {
+	params := url.Values{
+		"_pragma": {
+			"foreign_keys(1)",
+			"journal_mode(WAL)",
+			"busy_timeout(5000)",
+		},
+		"_txlock":      {"immediate"},
+		"_time_format": {"sqlite"},
+	}
+
+	db, err := sqlx.Open("sqlite", fmt.Sprintf("file:%s?%s", path, params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// now returns the current time in UTC with the microsecond precision Postgres
+// keeps, so stored timestamps compare as text.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// utc normalises a caller-supplied bound before it is compared with stored
+// timestamps.
+func utc(t time.Time) time.Time {
+	return t.UTC()
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlitedriver.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+		return true
+	}
+	return false
+}
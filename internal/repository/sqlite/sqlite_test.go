@@ -0,0 +1,25 @@
+package sqlite_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/milyrock/PR-Reviewer/internal/repository/sqlite"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+	"github.com/milyrock/PR-Reviewer/internal/test"
+	"github.com/milyrock/PR-Reviewer/internal/test/storetest"
+)
+
+var _ service.Store = (*sqlite.Store)(nil)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) service.Store {
+		db, err := test.SetupSQLiteDB(filepath.Join(t.TempDir(), "pr-reviewer.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		return sqlite.NewStore(db)
+	})
+}
@@ -0,0 +1,313 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository"
+	"github.com/milyrock/PR-Reviewer/internal/repository/aggregate"
+)
+
+const (
+	selectTimelineActivity = `
+		SELECT
+			pr.created_at,
+			pr.status,
+			(SELECT COUNT(*) FROM pr_reviewers prr WHERE prr.pull_request_id = pr.pull_request_id) AS reviewers
+		FROM pull_requests pr
+		INNER JOIN users a ON pr.author_id = a.user_id
+		%s
+	`
+
+	selectMergedPRs = `
+		SELECT pr.author_id, a.team_name, pr.created_at, pr.merged_at
+		FROM pull_requests pr
+		INNER JOIN users a ON pr.author_id = a.user_id
+		WHERE pr.status = 'MERGED' AND pr.merged_at IS NOT NULL %s
+	`
+
+	selectMergedAssignments = `
+		SELECT pr.author_id, a.team_name, pr.created_at, pr.merged_at, prr.user_id AS reviewer_id, prr.assigned_at
+		FROM pull_requests pr
+		INNER JOIN users a ON pr.author_id = a.user_id
+		INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		WHERE pr.status = 'MERGED' AND pr.merged_at IS NOT NULL %s
+	`
+
+	selectDecidedAssignments = `
+		SELECT pr.author_id, a.team_name, prr.user_id AS reviewer_id, prr.assigned_at, d.first_decided_at
+		FROM pull_requests pr
+		INNER JOIN users a ON pr.author_id = a.user_id
+		INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		INNER JOIN review_decisions d ON prr.pull_request_id = d.pull_request_id AND prr.user_id = d.user_id
+		WHERE d.first_decided_at IS NOT NULL %s
+	`
+
+	selectReviewEdges = `
+		SELECT pr.author_id, prr.user_id AS reviewer_id, COUNT(*) AS weight
+		FROM pull_requests pr
+		INNER JOIN users a ON pr.author_id = a.user_id
+		INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		%s
+		GROUP BY pr.author_id, prr.user_id
+		ORDER BY pr.author_id, prr.user_id
+	`
+
+	selectPRAuthors = `
+		SELECT DISTINCT pr.author_id
+		FROM pull_requests pr
+		INNER JOIN users a ON pr.author_id = a.user_id
+		%s
+		ORDER BY pr.author_id
+	`
+
+	selectGraphNodes = `
+		SELECT user_id, username, team_name
+		FROM users
+		WHERE user_id IN (?)
+		ORDER BY user_id
+	`
+)
+
+// latencyRow is one merged PR, one reviewer assignment of a merged PR for the
+// reviewer-based metrics, or one decided assignment for the decision metrics.
+type latencyRow struct {
+	AuthorID       string    `db:"author_id"`
+	TeamName       string    `db:"team_name"`
+	CreatedAt      time.Time `db:"created_at"`
+	MergedAt       time.Time `db:"merged_at"`
+	ReviewerID     string    `db:"reviewer_id"`
+	AssignedAt     time.Time `db:"assigned_at"`
+	FirstDecidedAt time.Time `db:"first_decided_at"`
+}
+
+func mergeDuration(row latencyRow) float64 {
+	return row.MergedAt.Sub(row.CreatedAt).Seconds()
+}
+
+func assignmentDuration(row latencyRow) float64 {
+	return row.MergedAt.Sub(row.AssignedAt).Seconds()
+}
+
+func mergedWeek(row latencyRow) string {
+	return aggregate.Week(row.MergedAt)
+}
+
+func decisionDuration(row latencyRow) float64 {
+	return row.FirstDecidedAt.Sub(row.AssignedAt).Seconds()
+}
+
+func decidedWeek(row latencyRow) string {
+	return aggregate.Week(row.FirstDecidedAt)
+}
+
+func reviewerKey(row latencyRow) string {
+	return row.ReviewerID
+}
+
+// latencyQuery describes one metric: the rows it samples, the column the
+// From/To window applies to, and the group key and duration of a row.
+type latencyQuery struct {
+	key      func(latencyRow) string
+	duration func(latencyRow) float64
+	query    string
+	at       string
+}
+
+var latencyQueries = map[string]latencyQuery{
+	repository.LatencyTimeToMergeByTeam: {
+		key:      func(row latencyRow) string { return row.TeamName },
+		duration: mergeDuration,
+		query:    selectMergedPRs,
+		at:       "pr.merged_at",
+	},
+	repository.LatencyTimeToMergeByAuthor: {
+		key:      func(row latencyRow) string { return row.AuthorID },
+		duration: mergeDuration,
+		query:    selectMergedPRs,
+		at:       "pr.merged_at",
+	},
+	repository.LatencyTimeToMergeByReviewer: {
+		key:      reviewerKey,
+		duration: mergeDuration,
+		query:    selectMergedAssignments,
+		at:       "pr.merged_at",
+	},
+	repository.LatencyTimeToMergeByWeek: {
+		key:      mergedWeek,
+		duration: mergeDuration,
+		query:    selectMergedPRs,
+		at:       "pr.merged_at",
+	},
+	repository.LatencyAssignmentToMergeByReviewer: {
+		key:      reviewerKey,
+		duration: assignmentDuration,
+		query:    selectMergedAssignments,
+		at:       "pr.merged_at",
+	},
+	repository.LatencyAssignmentToMergeByWeek: {
+		key:      mergedWeek,
+		duration: assignmentDuration,
+		query:    selectMergedAssignments,
+		at:       "pr.merged_at",
+	},
+	repository.LatencyAssignmentToFirstDecisionByReviewer: {
+		key:      reviewerKey,
+		duration: decisionDuration,
+		query:    selectDecidedAssignments,
+		at:       "d.first_decided_at",
+	},
+	repository.LatencyAssignmentToFirstDecisionByWeek: {
+		key:      decidedWeek,
+		duration: decisionDuration,
+		query:    selectDecidedAssignments,
+		at:       "d.first_decided_at",
+	},
+}
+
+func (s *Store) GetReviewTimeline(ctx context.Context, filter models.StatisticsFilter) ([]models.StatisticsBucket, error) {
+	switch filter.Bucket {
+	case "day", "week":
+	default:
+		return nil, fmt.Errorf("unsupported bucket %q", filter.Bucket)
+	}
+
+	conds, args := prStatisticsConds(filter)
+	if filter.TeamName != "" {
+		conds = append(conds, "a.team_name = ?")
+		args = append(args, filter.TeamName)
+	}
+
+	query := fmt.Sprintf(selectTimelineActivity, whereClause(conds))
+
+	var prs []aggregate.PRActivity
+	if err := s.db.SelectContext(ctx, &prs, query, args...); err != nil {
+		return nil, err
+	}
+
+	return aggregate.Timeline(prs, filter.Bucket), nil
+}
+
+// GetLatencyStats returns p50/p90/p99 durations in seconds for one of the
+// repository.Latency* metrics. The samples are read in SQL and the percentiles
+// computed in Go, since SQLite has no percentile_cont.
+func (s *Store) GetLatencyStats(ctx context.Context, metric string, filter models.StatisticsFilter) ([]models.LatencyStats, error) {
+	q, ok := latencyQueries[metric]
+	if !ok {
+		return nil, fmt.Errorf("unsupported latency metric %q", metric)
+	}
+
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	if filter.From != nil {
+		conds = append(conds, q.at+" >= ?")
+		args = append(args, utc(*filter.From))
+	}
+	if filter.To != nil {
+		conds = append(conds, q.at+" < ?")
+		args = append(args, utc(*filter.To))
+	}
+	if filter.TeamName != "" {
+		conds = append(conds, "a.team_name = ?")
+		args = append(args, filter.TeamName)
+	}
+
+	extra := ""
+	if len(conds) > 0 {
+		extra = "AND " + strings.Join(conds, " AND ")
+	}
+
+	var rows []latencyRow
+	if err := s.db.SelectContext(ctx, &rows, fmt.Sprintf(q.query, extra), args...); err != nil {
+		return nil, err
+	}
+
+	samples := make([]aggregate.Sample, 0, len(rows))
+	for _, row := range rows {
+		samples = append(samples, aggregate.Sample{Key: q.key(row), Seconds: q.duration(row)})
+	}
+
+	return aggregate.Latency(samples), nil
+}
+
+func (s *Store) GetReviewEdges(ctx context.Context, filter models.StatisticsFilter) ([]models.GraphEdge, error) {
+	conds, args := prStatisticsConds(filter)
+	if filter.TeamName != "" {
+		conds = append(conds, "a.team_name = ?")
+		args = append(args, filter.TeamName)
+	}
+
+	query := fmt.Sprintf(selectReviewEdges, whereClause(conds))
+
+	var edges []models.GraphEdge
+	err := s.db.SelectContext(ctx, &edges, query, args...)
+	return edges, err
+}
+
+func (s *Store) GetPRAuthors(ctx context.Context, filter models.StatisticsFilter) ([]string, error) {
+	conds, args := prStatisticsConds(filter)
+	if filter.TeamName != "" {
+		conds = append(conds, "a.team_name = ?")
+		args = append(args, filter.TeamName)
+	}
+
+	query := fmt.Sprintf(selectPRAuthors, whereClause(conds))
+
+	var authors []string
+	err := s.db.SelectContext(ctx, &authors, query, args...)
+	return authors, err
+}
+
+func (s *Store) GetGraphNodes(ctx context.Context, userIDs []string) ([]models.GraphNode, error) {
+	if len(userIDs) == 0 {
+		return []models.GraphNode{}, nil
+	}
+
+	query, args, err := sqlx.In(selectGraphNodes, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []models.GraphNode
+	err = s.db.SelectContext(ctx, &nodes, query, args...)
+	return nodes, err
+}
+
+// prStatisticsConds returns the conditions on the pull_requests row (aliased pr)
+// shared by all statistics queries.
+func prStatisticsConds(filter models.StatisticsFilter) ([]string, []interface{}) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	if filter.From != nil {
+		conds = append(conds, "pr.created_at >= ?")
+		args = append(args, utc(*filter.From))
+	}
+	if filter.To != nil {
+		conds = append(conds, "pr.created_at < ?")
+		args = append(args, utc(*filter.To))
+	}
+	if filter.Status != "" {
+		conds = append(conds, "pr.status = ?")
+		args = append(args, filter.Status)
+	}
+
+	return conds, args
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conds, " AND ")
+}
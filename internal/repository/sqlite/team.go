@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository"
+)
+
+const (
+	insertTeam = `INSERT INTO teams (team_name) VALUES (?) ON CONFLICT (team_name) DO NOTHING`
+
+	teamExists = `SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = ?)`
+
+	selectTeamMembers = `
+		SELECT user_id, username, is_active
+		FROM users
+		WHERE team_name = ?
+		ORDER BY user_id
+	`
+
+	insertOrUpdateUser = `
+		INSERT INTO users (user_id, username, team_name, is_active, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE
+		SET username = excluded.username, team_name = excluded.team_name, is_active = excluded.is_active
+	`
+)
+
+func (s *Store) CreateTeam(ctx context.Context, teamName string, members []models.TeamMember) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer repository.Rollback(ctx, tx)
+
+	_, err = tx.ExecContext(ctx, insertTeam, teamName)
+	if err != nil {
+		return err
+	}
+
+	createdAt := now()
+	for _, member := range members {
+		_, err = tx.ExecContext(ctx, insertOrUpdateUser, member.UserID, member.Username, teamName, member.IsActive, createdAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) TeamExists(ctx context.Context, teamName string) (bool, error) {
+	var exists bool
+	err := s.db.GetContext(ctx, &exists, teamExists, teamName)
+	return exists, err
+}
+
+func (s *Store) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
+	exists, err := s.TeamExists(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	var team models.Team
+	team.TeamName = teamName
+
+	err = s.db.SelectContext(ctx, &team.Members, selectTeamMembers, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &team, nil
+}
@@ -0,0 +1,152 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+const (
+	selectUser = `
+		SELECT user_id, username, team_name, is_active
+		FROM users
+		WHERE user_id = ?
+	`
+
+	updateUserActive = `
+		UPDATE users
+		SET is_active = ?
+		WHERE user_id = ?
+	`
+
+	selectActiveUsersByTeam = `
+		SELECT user_id, username, team_name, is_active
+		FROM users
+		WHERE team_name = ? AND is_active = true AND user_id != ?
+		ORDER BY user_id
+	`
+
+	selectUserReviewPRs = `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
+		FROM pull_requests pr
+		INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		WHERE prr.user_id = ?
+		ORDER BY pr.created_at DESC
+	`
+
+	selectUserOpenReviewPRs = `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
+		FROM pull_requests pr
+		INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		WHERE prr.user_id = ? AND pr.status = 'OPEN'
+		ORDER BY pr.created_at DESC
+	`
+
+	selectUserReviewStats = `
+		SELECT
+			u.user_id,
+			u.username,
+			COUNT(pr.pull_request_id) AS review_count
+		FROM users u
+		LEFT JOIN pr_reviewers prr ON u.user_id = prr.user_id
+		LEFT JOIN pull_requests pr ON prr.pull_request_id = pr.pull_request_id %s
+		%s
+		GROUP BY u.user_id, u.username
+		ORDER BY review_count DESC, u.user_id
+	`
+
+	selectActiveMemberAssignments = `
+		SELECT u.user_id, u.username, u.team_name, u.created_at, COUNT(prr.pull_request_id) AS assignments
+		FROM users u
+		LEFT JOIN pr_reviewers prr ON u.user_id = prr.user_id
+			AND prr.assigned_at >= ? AND prr.assigned_at < ?
+		WHERE u.is_active = true %s
+		GROUP BY u.user_id, u.username, u.team_name, u.created_at
+		ORDER BY u.team_name, u.user_id
+	`
+)
+
+func (s *Store) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	var user models.User
+	err := s.db.GetContext(ctx, &user, selectUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *Store) SetUserIsActive(ctx context.Context, userID string, isActive bool) error {
+	result, err := s.db.ExecContext(ctx, updateUserActive, isActive, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (s *Store) GetActiveUsersByTeamName(ctx context.Context, teamName string, excludeUserID string) ([]models.User, error) {
+	var users []models.User
+	err := s.db.SelectContext(ctx, &users, selectActiveUsersByTeam, teamName, excludeUserID)
+	return users, err
+}
+
+func (s *Store) GetUserReviewPRs(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
+	var prs []models.PullRequestShort
+	err := s.db.SelectContext(ctx, &prs, selectUserReviewPRs, userID)
+	return prs, err
+}
+
+func (s *Store) GetUserOpenReviewPRs(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
+	var prs []models.PullRequestShort
+	err := s.db.SelectContext(ctx, &prs, selectUserOpenReviewPRs, userID)
+	return prs, err
+}
+
+func (s *Store) GetActiveMemberAssignments(ctx context.Context, from, to time.Time, teamName string) ([]models.MemberAssignments, error) {
+	args := []interface{}{utc(from), utc(to)}
+
+	teamClause := ""
+	if teamName != "" {
+		teamClause = "AND u.team_name = ?"
+		args = append(args, teamName)
+	}
+
+	query := fmt.Sprintf(selectActiveMemberAssignments, teamClause)
+
+	var members []models.MemberAssignments
+	err := s.db.SelectContext(ctx, &members, query, args...)
+	return members, err
+}
+
+func (s *Store) GetUserReviewStats(ctx context.Context, filter models.StatisticsFilter) ([]models.UserReviewStats, error) {
+	prConds, args := prStatisticsConds(filter)
+
+	joinClause := ""
+	if len(prConds) > 0 {
+		joinClause = "AND " + strings.Join(prConds, " AND ")
+	}
+
+	whereClause := ""
+	if filter.TeamName != "" {
+		whereClause = "WHERE u.team_name = ?"
+		args = append(args, filter.TeamName)
+	}
+
+	query := fmt.Sprintf(selectUserReviewStats, joinClause, whereClause)
+
+	var stats []models.UserReviewStats
+	err := s.db.SelectContext(ctx, &stats, query, args...)
+	return stats, err
+}
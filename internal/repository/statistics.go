@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+const (
+	selectReviewTimeline = `
+		SELECT
+			date_trunc('%s', pr.created_at) AS period_start,
+			COUNT(DISTINCT pr.pull_request_id) AS prs_created,
+			COUNT(DISTINCT pr.pull_request_id) FILTER (WHERE pr.status = 'MERGED') AS prs_merged,
+			COUNT(prr.user_id) AS assignments
+		FROM pull_requests pr
+		INNER JOIN users a ON pr.author_id = a.user_id
+		LEFT JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		%s
+		GROUP BY period_start
+		ORDER BY period_start
+	`
+
+	selectLatency = `
+		SELECT
+			%[1]s AS key,
+			COUNT(*) AS count,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY %[2]s) AS p50,
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY %[2]s) AS p90,
+			percentile_cont(0.99) WITHIN GROUP (ORDER BY %[2]s) AS p99
+		FROM pull_requests pr
+		INNER JOIN users a ON pr.author_id = a.user_id
+		%[3]s
+		WHERE %[4]s IS NOT NULL %[5]s
+		GROUP BY key
+		ORDER BY key
+	`
+)
+
+const (
+	selectReviewEdges = `
+		SELECT pr.author_id, prr.user_id AS reviewer_id, COUNT(*) AS weight
+		FROM pull_requests pr
+		INNER JOIN users a ON pr.author_id = a.user_id
+		INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		%s
+		GROUP BY pr.author_id, prr.user_id
+		ORDER BY pr.author_id, prr.user_id
+	`
+
+	selectPRAuthors = `
+		SELECT DISTINCT pr.author_id
+		FROM pull_requests pr
+		INNER JOIN users a ON pr.author_id = a.user_id
+		%s
+		ORDER BY pr.author_id
+	`
+
+	selectGraphNodes = `
+		SELECT user_id, username, team_name
+		FROM users
+		WHERE user_id IN (?)
+		ORDER BY user_id
+	`
+)
+
+const (
+	mergedAt           = "pr.merged_at"
+	mergeDuration      = "EXTRACT(EPOCH FROM pr.merged_at - pr.created_at)"
+	assignmentDuration = "EXTRACT(EPOCH FROM pr.merged_at - prr.assigned_at)"
+	reviewersJoin      = "INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id"
+	mergedWeek         = "to_char(date_trunc('week', pr.merged_at), 'YYYY-MM-DD')"
+
+	decidedAt        = "d.first_decided_at"
+	decisionDuration = "EXTRACT(EPOCH FROM d.first_decided_at - prr.assigned_at)"
+	decisionsJoin    = reviewersJoin + " INNER JOIN review_decisions d ON prr.pull_request_id = d.pull_request_id AND prr.user_id = d.user_id"
+	decidedWeek      = "to_char(date_trunc('week', d.first_decided_at), 'YYYY-MM-DD')"
+)
+
+const (
+	LatencyTimeToMergeByTeam                   = "time_to_merge_by_team"
+	LatencyTimeToMergeByAuthor                 = "time_to_merge_by_author"
+	LatencyTimeToMergeByReviewer               = "time_to_merge_by_reviewer"
+	LatencyTimeToMergeByWeek                   = "time_to_merge_by_week"
+	LatencyAssignmentToMergeByReviewer         = "assignment_to_merge_by_reviewer"
+	LatencyAssignmentToMergeByWeek             = "assignment_to_merge_by_week"
+	LatencyAssignmentToFirstDecisionByReviewer = "assignment_to_first_decision_by_reviewer"
+	LatencyAssignmentToFirstDecisionByWeek     = "assignment_to_first_decision_by_week"
+)
+
+// latencyQuery describes one metric: the group key, the duration in seconds
+// and the timestamp that must be set and that the From/To window applies to.
+type latencyQuery struct {
+	key      string
+	duration string
+	at       string
+	join     string
+}
+
+var latencyQueries = map[string]latencyQuery{
+	LatencyTimeToMergeByTeam:                   {key: "a.team_name", duration: mergeDuration, at: mergedAt},
+	LatencyTimeToMergeByAuthor:                 {key: "pr.author_id", duration: mergeDuration, at: mergedAt},
+	LatencyTimeToMergeByReviewer:               {key: "prr.user_id", duration: mergeDuration, at: mergedAt, join: reviewersJoin},
+	LatencyTimeToMergeByWeek:                   {key: mergedWeek, duration: mergeDuration, at: mergedAt},
+	LatencyAssignmentToMergeByReviewer:         {key: "prr.user_id", duration: assignmentDuration, at: mergedAt, join: reviewersJoin},
+	LatencyAssignmentToMergeByWeek:             {key: mergedWeek, duration: assignmentDuration, at: mergedAt, join: reviewersJoin},
+	LatencyAssignmentToFirstDecisionByReviewer: {key: "prr.user_id", duration: decisionDuration, at: decidedAt, join: decisionsJoin},
+	LatencyAssignmentToFirstDecisionByWeek:     {key: decidedWeek, duration: decisionDuration, at: decidedAt, join: decisionsJoin},
+}
+
+func (r *Repository) GetReviewTimeline(ctx context.Context, filter models.StatisticsFilter) ([]models.StatisticsBucket, error) {
+	conds, args := prStatisticsConds(filter)
+	if filter.TeamName != "" {
+		conds = append(conds, "a.team_name = ?")
+		args = append(args, filter.TeamName)
+	}
+
+	var unit string
+	switch filter.Bucket {
+	case "day", "week":
+		unit = filter.Bucket
+	default:
+		return nil, fmt.Errorf("unsupported bucket %q", filter.Bucket)
+	}
+
+	query := fmt.Sprintf(selectReviewTimeline, unit, whereClause(conds))
+
+	var buckets []models.StatisticsBucket
+	err := r.db.SelectContext(ctx, &buckets, r.db.Rebind(query), args...)
+	return buckets, err
+}
+
+// GetLatencyStats returns p50/p90/p99 durations in seconds for one of the
+// Latency* metrics. The merge metrics consider merged PRs and window on
+// merged_at; the decision metrics consider reviewers who have made a decision
+// and window on the first decision.
+func (r *Repository) GetLatencyStats(ctx context.Context, metric string, filter models.StatisticsFilter) ([]models.LatencyStats, error) {
+	q, ok := latencyQueries[metric]
+	if !ok {
+		return nil, fmt.Errorf("unsupported latency metric %q", metric)
+	}
+
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	if filter.From != nil {
+		conds = append(conds, q.at+" >= ?")
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		conds = append(conds, q.at+" < ?")
+		args = append(args, *filter.To)
+	}
+	if filter.TeamName != "" {
+		conds = append(conds, "a.team_name = ?")
+		args = append(args, filter.TeamName)
+	}
+
+	extra := ""
+	if len(conds) > 0 {
+		extra = "AND " + strings.Join(conds, " AND ")
+	}
+
+	query := fmt.Sprintf(selectLatency, q.key, q.duration, q.join, q.at, extra)
+
+	var stats []models.LatencyStats
+	err := r.db.SelectContext(ctx, &stats, r.db.Rebind(query), args...)
+	return stats, err
+}
+
+func (r *Repository) GetReviewEdges(ctx context.Context, filter models.StatisticsFilter) ([]models.GraphEdge, error) {
+	conds, args := prStatisticsConds(filter)
+	if filter.TeamName != "" {
+		conds = append(conds, "a.team_name = ?")
+		args = append(args, filter.TeamName)
+	}
+
+	query := fmt.Sprintf(selectReviewEdges, whereClause(conds))
+
+	var edges []models.GraphEdge
+	err := r.db.SelectContext(ctx, &edges, r.db.Rebind(query), args...)
+	return edges, err
+}
+
+// GetPRAuthors returns the authors of the PRs that GetReviewEdges considers,
+// including those whose PRs have no reviewers.
+func (r *Repository) GetPRAuthors(ctx context.Context, filter models.StatisticsFilter) ([]string, error) {
+	conds, args := prStatisticsConds(filter)
+	if filter.TeamName != "" {
+		conds = append(conds, "a.team_name = ?")
+		args = append(args, filter.TeamName)
+	}
+
+	query := fmt.Sprintf(selectPRAuthors, whereClause(conds))
+
+	var authors []string
+	err := r.db.SelectContext(ctx, &authors, r.db.Rebind(query), args...)
+	return authors, err
+}
+
+func (r *Repository) GetGraphNodes(ctx context.Context, userIDs []string) ([]models.GraphNode, error) {
+	if len(userIDs) == 0 {
+		return []models.GraphNode{}, nil
+	}
+
+	query, args, err := sqlx.In(selectGraphNodes, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []models.GraphNode
+	err = r.db.SelectContext(ctx, &nodes, r.db.Rebind(query), args...)
+	return nodes, err
+}
+
+// prStatisticsConds returns the conditions on the pull_requests row (aliased pr)
+// shared by all statistics queries.
+func prStatisticsConds(filter models.StatisticsFilter) ([]string, []interface{}) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	if filter.From != nil {
+		conds = append(conds, "pr.created_at >= ?")
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		conds = append(conds, "pr.created_at < ?")
+		args = append(args, *filter.To)
+	}
+	if filter.Status != "" {
+		conds = append(conds, "pr.status = ?")
+		args = append(args, filter.Status)
+	}
+
+	return conds, args
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conds, " AND ")
+}
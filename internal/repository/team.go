@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/milyrock/PR-Reviewer/internal/models"
@@ -26,21 +27,21 @@ const (
 	`
 )
 
-func (r *Repository) CreateTeam(teamName string, members []models.TeamMember) error {
-	tx, err := r.db.Beginx()
+func (r *Repository) CreateTeam(ctx context.Context, teamName string, members []models.TeamMember) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	defer tx.Rollback() //nolint:errcheck
+	defer Rollback(ctx, tx)
 
-	_, err = tx.Exec(insertTeam, teamName)
+	_, err = tx.ExecContext(ctx, insertTeam, teamName)
 	if err != nil {
 		return err
 	}
 
 	for _, member := range members {
-		_, err = tx.Exec(insertOrUpdateUser, member.UserID, member.Username, teamName, member.IsActive)
+		_, err = tx.ExecContext(ctx, insertOrUpdateUser, member.UserID, member.Username, teamName, member.IsActive)
 		if err != nil {
 			return err
 		}
@@ -49,14 +50,14 @@ func (r *Repository) CreateTeam(teamName string, members []models.TeamMember) er
 	return tx.Commit()
 }
 
-func (r *Repository) TeamExists(teamName string) (bool, error) {
+func (r *Repository) TeamExists(ctx context.Context, teamName string) (bool, error) {
 	var exists bool
-	err := r.db.Get(&exists, teamExists, teamName)
+	err := r.db.GetContext(ctx, &exists, teamExists, teamName)
 	return exists, err
 }
 
-func (r *Repository) GetTeam(teamName string) (*models.Team, error) {
-	exists, err := r.TeamExists(teamName)
+func (r *Repository) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
+	exists, err := r.TeamExists(ctx, teamName)
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +68,7 @@ func (r *Repository) GetTeam(teamName string) (*models.Team, error) {
 	var team models.Team
 	team.TeamName = teamName
 
-	err = r.db.Select(&team.Members, selectTeamMembers, teamName)
+	err = r.db.SelectContext(ctx, &team.Members, selectTeamMembers, teamName)
 	if err != nil {
 		return nil, err
 	}
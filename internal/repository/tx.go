@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Rollback is deferred after BeginTxx. It is a no-op once the transaction has
+// been committed, or rolled back by database/sql because ctx ended; any other
+// failure is logged against the request.
+func Rollback(ctx context.Context, tx *sqlx.Tx) {
+	if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		slog.WarnContext(ctx, "failed to roll back transaction", "error", err)
+	}
+}
@@ -1,7 +1,11 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/milyrock/PR-Reviewer/internal/models"
 )
@@ -34,29 +38,49 @@ const (
 		ORDER BY pr.created_at DESC
 	`
 
+	selectUserOpenReviewPRs = `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
+		FROM pull_requests pr
+		INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		WHERE prr.user_id = $1 AND pr.status = 'OPEN'
+		ORDER BY pr.created_at DESC
+	`
+
 	selectUserReviewStats = `
 		SELECT 
 			u.user_id,
 			u.username,
-			COUNT(prr.pull_request_id) as review_count
+			COUNT(pr.pull_request_id) as review_count
 		FROM users u
 		LEFT JOIN pr_reviewers prr ON u.user_id = prr.user_id
+		LEFT JOIN pull_requests pr ON prr.pull_request_id = pr.pull_request_id %s
+		%s
 		GROUP BY u.user_id, u.username
 		ORDER BY review_count DESC, u.user_id
 	`
+
+	selectActiveMemberAssignments = `
+		SELECT u.user_id, u.username, u.team_name, u.created_at, COUNT(prr.pull_request_id) AS assignments
+		FROM users u
+		LEFT JOIN pr_reviewers prr ON u.user_id = prr.user_id
+			AND prr.assigned_at >= ? AND prr.assigned_at < ?
+		WHERE u.is_active = true %s
+		GROUP BY u.user_id, u.username, u.team_name, u.created_at
+		ORDER BY u.team_name, u.user_id
+	`
 )
 
-func (r *Repository) GetUser(userID string) (*models.User, error) {
+func (r *Repository) GetUser(ctx context.Context, userID string) (*models.User, error) {
 	var user models.User
-	err := r.db.Get(&user, selectUser, userID)
+	err := r.db.GetContext(ctx, &user, selectUser, userID)
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *Repository) SetUserIsActive(userID string, isActive bool) error {
-	result, err := r.db.Exec(updateUserActive, isActive, userID)
+func (r *Repository) SetUserIsActive(ctx context.Context, userID string, isActive bool) error {
+	result, err := r.db.ExecContext(ctx, updateUserActive, isActive, userID)
 	if err != nil {
 		return err
 	}
@@ -72,20 +96,57 @@ func (r *Repository) SetUserIsActive(userID string, isActive bool) error {
 	return nil
 }
 
-func (r *Repository) GetActiveUsersByTeamName(teamName string, excludeUserID string) ([]models.User, error) {
+func (r *Repository) GetActiveUsersByTeamName(ctx context.Context, teamName string, excludeUserID string) ([]models.User, error) {
 	var users []models.User
-	err := r.db.Select(&users, selectActiveUsersByTeam, teamName, excludeUserID)
+	err := r.db.SelectContext(ctx, &users, selectActiveUsersByTeam, teamName, excludeUserID)
 	return users, err
 }
 
-func (r *Repository) GetUserReviewPRs(userID string) ([]models.PullRequestShort, error) {
+func (r *Repository) GetUserReviewPRs(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
+	var prs []models.PullRequestShort
+	err := r.db.SelectContext(ctx, &prs, selectUserReviewPRs, userID)
+	return prs, err
+}
+
+func (r *Repository) GetUserOpenReviewPRs(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
 	var prs []models.PullRequestShort
-	err := r.db.Select(&prs, selectUserReviewPRs, userID)
+	err := r.db.SelectContext(ctx, &prs, selectUserOpenReviewPRs, userID)
 	return prs, err
 }
 
-func (r *Repository) GetUserReviewStats() ([]models.UserReviewStats, error) {
+func (r *Repository) GetActiveMemberAssignments(ctx context.Context, from, to time.Time, teamName string) ([]models.MemberAssignments, error) {
+	args := []interface{}{from, to}
+
+	teamClause := ""
+	if teamName != "" {
+		teamClause = "AND u.team_name = ?"
+		args = append(args, teamName)
+	}
+
+	query := fmt.Sprintf(selectActiveMemberAssignments, teamClause)
+
+	var members []models.MemberAssignments
+	err := r.db.SelectContext(ctx, &members, r.db.Rebind(query), args...)
+	return members, err
+}
+
+func (r *Repository) GetUserReviewStats(ctx context.Context, filter models.StatisticsFilter) ([]models.UserReviewStats, error) {
+	prConds, args := prStatisticsConds(filter)
+
+	joinClause := ""
+	if len(prConds) > 0 {
+		joinClause = "AND " + strings.Join(prConds, " AND ")
+	}
+
+	whereClause := ""
+	if filter.TeamName != "" {
+		whereClause = "WHERE u.team_name = ?"
+		args = append(args, filter.TeamName)
+	}
+
+	query := fmt.Sprintf(selectUserReviewStats, joinClause, whereClause)
+
 	var stats []models.UserReviewStats
-	err := r.db.Select(&stats, selectUserReviewStats)
+	err := r.db.SelectContext(ctx, &stats, r.db.Rebind(query), args...)
 	return stats, err
 }
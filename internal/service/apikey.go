@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+const (
+	apiKeyPrefix   = "prr_"
+	bootstrapKeyID = "bootstrap"
+)
+
+type APIKeyService struct {
+	repo          Store
+	bootstrapHash []byte
+}
+
+func NewAPIKeyService(repo Store, bootstrapKey string) *APIKeyService {
+	s := &APIKeyService{repo: repo}
+	if bootstrapKey != "" {
+		hash := sha256.Sum256([]byte(bootstrapKey))
+		s.bootstrapHash = hash[:]
+	}
+	return s
+}
+
+// CreateKey returns the stored key together with its plaintext value. Only the
+// SHA-256 hash is persisted, so the plaintext cannot be recovered later.
+func (s *APIKeyService) CreateKey(ctx context.Context, req models.CreateAPIKeyRequest) (*models.APIKey, string, error) {
+	if err := validateCreateAPIKey(req); err != nil {
+		return nil, "", err
+	}
+
+	keyID, err := randomString(8, hex.EncodeToString)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rawKey := apiKeyPrefix + keyID + "_" + secret
+	key := &models.APIKey{
+		KeyID:     keyID,
+		Name:      req.Name,
+		Role:      req.Role,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.repo.CreateAPIKey(ctx, key, hashKey(rawKey)); err != nil {
+		return nil, "", err
+	}
+
+	slog.InfoContext(ctx, "api key created", "key_id", key.KeyID, "key_role", key.Role)
+
+	return key, rawKey, nil
+}
+
+func (s *APIKeyService) ListKeys(ctx context.Context) ([]models.APIKey, error) {
+	keys, err := s.repo.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+	return keys, nil
+}
+
+func (s *APIKeyService) RevokeKey(ctx context.Context, keyID string) error {
+	if err := s.repo.RevokeAPIKey(ctx, keyID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+
+	slog.InfoContext(ctx, "api key revoked", "key_id", keyID)
+	return nil
+}
+
+func (s *APIKeyService) Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	if s.bootstrapHash != nil {
+		hash := sha256.Sum256([]byte(rawKey))
+		if subtle.ConstantTimeCompare(hash[:], s.bootstrapHash) == 1 {
+			return &models.APIKey{KeyID: bootstrapKeyID, Name: bootstrapKeyID, Role: models.RoleAdmin}, nil
+		}
+	}
+
+	key, err := s.repo.GetAPIKeyByHash(ctx, hashKey(rawKey))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func validRole(role string) bool {
+	switch role {
+	case models.RoleAdmin, models.RoleIntegration, models.RoleReadOnly:
+		return true
+	}
+	return false
+}
+
+func hashKey(rawKey string) string {
+	hash := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(hash[:])
+}
+
+func randomString(n int, encode func([]byte) string) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return encode(buf), nil
+}
@@ -1,14 +1,51 @@
 package service
 
-import "errors"
+import (
+	"net/http"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+// Error is a domain error. It carries everything a handler needs to answer
+// the client: the API error code, the HTTP status and a message that is safe
+// to expose, plus the offending fields for validation errors. Any error that
+// is not an *Error is internal and must not reach the client verbatim.
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+	Details []models.FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
 
 var (
-	ErrPRExists            = errors.New("PR id already exists")
-	ErrPRNotFound          = errors.New("resource not found")
-	ErrPRMerged            = errors.New("cannot reassign on merged PR")
-	ErrReviewerNotAssigned = errors.New("reviewer is not assigned to this PR")
-	ErrNoCandidate         = errors.New("no active replacement candidate in team")
-	ErrUserNotFound        = errors.New("resource not found")
-	ErrTeamExists          = errors.New("team_name already exists")
-	ErrTeamNotFound        = errors.New("resource not found")
+	ErrPRExists                 = &Error{Code: "PR_EXISTS", Status: http.StatusConflict, Message: "PR id already exists"}
+	ErrPRNotFound               = &Error{Code: "PR_NOT_FOUND", Status: http.StatusNotFound, Message: "pull request not found"}
+	ErrPRMerged                 = &Error{Code: "PR_MERGED", Status: http.StatusConflict, Message: "cannot reassign on merged PR"}
+	ErrReviewMerged             = &Error{Code: "PR_MERGED", Status: http.StatusConflict, Message: "cannot review a merged PR"}
+	ErrReviewerNotAssigned      = &Error{Code: "NOT_ASSIGNED", Status: http.StatusConflict, Message: "reviewer is not assigned to this PR"}
+	ErrNoCandidate              = &Error{Code: "NO_CANDIDATE", Status: http.StatusConflict, Message: "no active replacement candidate in team"}
+	ErrUserNotFound             = &Error{Code: "USER_NOT_FOUND", Status: http.StatusNotFound, Message: "user not found"}
+	ErrTeamExists               = &Error{Code: "TEAM_EXISTS", Status: http.StatusBadRequest, Message: "team_name already exists"}
+	ErrTeamNotFound             = &Error{Code: "TEAM_NOT_FOUND", Status: http.StatusNotFound, Message: "team not found"}
+	ErrInvalidCursor            = &Error{Code: "INVALID_REQUEST", Status: http.StatusBadRequest, Message: "invalid cursor"}
+	ErrAPIKeyNotFound           = &Error{Code: "NOT_FOUND", Status: http.StatusNotFound, Message: "resource not found"}
+	ErrUnauthorized             = &Error{Code: "UNAUTHORIZED", Status: http.StatusUnauthorized, Message: "missing or invalid credentials"}
+	ErrIdempotencyReused        = &Error{Code: "IDEMPOTENCY_KEY_REUSED", Status: http.StatusUnprocessableEntity, Message: "idempotency key was used with a different request"}
+	ErrIdempotencyPending       = &Error{Code: "IDEMPOTENCY_IN_PROGRESS", Status: http.StatusConflict, Message: "a request with this idempotency key is still in progress"}
+	ErrIdempotencyNotReplayable = &Error{Code: "IDEMPOTENCY_NOT_REPLAYABLE", Status: http.StatusConflict, Message: "a request with this idempotency key was already processed and its response cannot be replayed"}
+	ErrTimeout                  = &Error{Code: "TIMEOUT", Status: http.StatusGatewayTimeout, Message: "request timed out"}
 )
+
+// NewValidationError reports the fields that make a request invalid.
+func NewValidationError(details ...models.FieldError) *Error {
+	return &Error{
+		Code:    "INVALID_REQUEST",
+		Status:  http.StatusBadRequest,
+		Message: "request validation failed",
+		Details: details,
+	}
+}
@@ -0,0 +1,23 @@
+package service_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/milyrock/PR-Reviewer/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDomainErrorSurvivesWrapping(t *testing.T) {
+	err := fmt.Errorf("reassign pr-1: %w", service.ErrNoCandidate)
+
+	var domainErr *service.Error
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, "NO_CANDIDATE", domainErr.Code)
+	assert.Equal(t, http.StatusConflict, domainErr.Status)
+	assert.ErrorIs(t, err, service.ErrNoCandidate)
+	assert.NotErrorIs(t, err, service.ErrPRMerged)
+}
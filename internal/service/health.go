@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+const (
+	// readinessCheckTimeout bounds each dependency check so a hung database
+	// fails the probe instead of stalling it.
+	readinessCheckTimeout = 2 * time.Second
+
+	// staleHeartbeatIntervals is how many missed intervals make a worker count
+	// as stuck.
+	staleHeartbeatIntervals = 2
+
+	// Check failures are reported with one of these fixed messages; the
+	// underlying error is only logged, since /health/ready is unauthenticated.
+	checkErrorTimeout        = "timeout"
+	checkErrorUnreachable    = "unreachable"
+	checkErrorSchemaMismatch = "schema version mismatch"
+	// workerErrorLastRun stands in for the error of a failed worker run,
+	// which the worker has already logged.
+	workerErrorLastRun = "last run failed"
+)
+
+var errSchemaMismatch = errors.New(checkErrorSchemaMismatch)
+
+type HeartbeatSource interface {
+	Heartbeats() []models.WorkerHeartbeat
+}
+
+type HealthService struct {
+	repo    Store
+	workers HeartbeatSource
+	now     func() time.Time
+}
+
+// NewHealthService checks repo and, when workers is not nil, the heartbeats of
+// the background jobs.
+func NewHealthService(repo Store, workers HeartbeatSource) *HealthService {
+	return &HealthService{repo: repo, workers: workers, now: time.Now}
+}
+
+// Ready runs every readiness check. The report status is fail when any check
+// fails.
+func (s *HealthService) Ready(ctx context.Context) models.HealthReport {
+	checks := []models.HealthCheck{
+		s.run(ctx, "database", func(ctx context.Context) (interface{}, error) {
+			return nil, s.repo.Ping(ctx)
+		}),
+		s.run(ctx, "schema", func(ctx context.Context) (interface{}, error) {
+			version, err := s.repo.SchemaVersion(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if version.Current != version.Expected {
+				return version, fmt.Errorf("%w: schema version %d, binary expects %d", errSchemaMismatch, version.Current, version.Expected)
+			}
+			return version, nil
+		}),
+	}
+
+	if s.workers != nil {
+		for _, heartbeat := range s.workers.Heartbeats() {
+			checks = append(checks, s.checkHeartbeat(heartbeat))
+		}
+	}
+
+	report := models.HealthReport{Status: models.HealthStatusOK, Checks: checks}
+	for _, check := range checks {
+		if check.Status != models.HealthStatusOK {
+			report.Status = models.HealthStatusFail
+		}
+	}
+
+	return report
+}
+
+func (s *HealthService) run(ctx context.Context, name string, check func(ctx context.Context) (interface{}, error)) models.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	start := s.now()
+	details, err := check(ctx)
+
+	result := models.HealthCheck{
+		Name:      name,
+		Status:    models.HealthStatusOK,
+		LatencyMS: float64(s.now().Sub(start).Microseconds()) / 1000,
+		Details:   details,
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "readiness check failed", "check", name, "error", err)
+		result.Status = models.HealthStatusFail
+		result.Error = checkError(err)
+	}
+
+	return result
+}
+
+func checkError(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return checkErrorTimeout
+	case errors.Is(err, errSchemaMismatch):
+		return checkErrorSchemaMismatch
+	default:
+		return checkErrorUnreachable
+	}
+}
+
+type heartbeatDetails struct {
+	Interval  string     `json:"interval"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// checkHeartbeat fails a worker that has not completed a run within
+// staleHeartbeatIntervals of its schedule. A failed run still counts as a
+// heartbeat; the failure is reported but does not fail the probe.
+func (s *HealthService) checkHeartbeat(heartbeat models.WorkerHeartbeat) models.HealthCheck {
+	last := heartbeat.StartedAt
+	if heartbeat.LastRunAt != nil {
+		last = *heartbeat.LastRunAt
+	}
+
+	lastError := ""
+	if heartbeat.LastError != "" {
+		lastError = workerErrorLastRun
+	}
+
+	result := models.HealthCheck{
+		Name:   "worker:" + heartbeat.Name,
+		Status: models.HealthStatusOK,
+		Details: heartbeatDetails{
+			Interval:  heartbeat.Interval.String(),
+			LastRunAt: heartbeat.LastRunAt,
+			LastError: lastError,
+		},
+	}
+
+	if since := s.now().Sub(last); since > staleHeartbeatIntervals*heartbeat.Interval {
+		result.Status = models.HealthStatusFail
+		result.Error = fmt.Sprintf("no heartbeat for %s", since.Round(time.Second))
+	}
+
+	return result
+}
@@ -0,0 +1,135 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository/memory"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type heartbeats []models.WorkerHeartbeat
+
+func (h heartbeats) Heartbeats() []models.WorkerHeartbeat {
+	return h
+}
+
+// outdatedStore reports a schema older than the binary expects.
+type outdatedStore struct {
+	*memory.Store
+}
+
+func (s outdatedStore) SchemaVersion(ctx context.Context) (models.SchemaVersion, error) {
+	return models.SchemaVersion{Current: 0, Expected: 1}, nil
+}
+
+// unreachableStore fails the ping with an error that must not reach clients.
+type unreachableStore struct {
+	*memory.Store
+	err error
+}
+
+func (s unreachableStore) Ping(ctx context.Context) error {
+	if s.err == nil {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return s.err
+}
+
+func checksByName(report models.HealthReport) map[string]models.HealthCheck {
+	checks := make(map[string]models.HealthCheck, len(report.Checks))
+	for _, check := range report.Checks {
+		checks[check.Name] = check
+	}
+	return checks
+}
+
+func TestHealthReady(t *testing.T) {
+	lastRun := time.Now().Add(-time.Minute)
+	svc := service.NewHealthService(memory.New(), heartbeats{
+		{Name: "fresh", Interval: time.Minute, StartedAt: time.Now().Add(-time.Hour), LastRunAt: &lastRun, LastError: `pq: relation "idempotency_keys" does not exist`},
+		{Name: "waiting", Interval: time.Minute, StartedAt: time.Now()},
+	})
+
+	report := svc.Ready(context.Background())
+
+	assert.Equal(t, models.HealthStatusOK, report.Status)
+	checks := checksByName(report)
+	require.Len(t, checks, 4)
+	for _, name := range []string{"database", "schema", "worker:fresh", "worker:waiting"} {
+		assert.Equal(t, models.HealthStatusOK, checks[name].Status, name)
+	}
+	assert.GreaterOrEqual(t, checks["database"].LatencyMS, 0.0)
+
+	details, err := json.Marshal(checks["worker:fresh"].Details)
+	require.NoError(t, err)
+	assert.Contains(t, string(details), `"last_error":"last run failed"`)
+	assert.NotContains(t, string(details), "idempotency_keys")
+}
+
+func TestHealthReadyFailsOnStaleWorker(t *testing.T) {
+	svc := service.NewHealthService(memory.New(), heartbeats{
+		{Name: "stuck", Interval: time.Minute, StartedAt: time.Now().Add(-time.Hour)},
+	})
+
+	report := svc.Ready(context.Background())
+
+	assert.Equal(t, models.HealthStatusFail, report.Status)
+	check := checksByName(report)["worker:stuck"]
+	assert.Equal(t, models.HealthStatusFail, check.Status)
+	assert.Contains(t, check.Error, "no heartbeat")
+}
+
+func TestHealthReadyFailsOnSchemaMismatch(t *testing.T) {
+	svc := service.NewHealthService(outdatedStore{memory.New()}, nil)
+
+	report := svc.Ready(context.Background())
+
+	assert.Equal(t, models.HealthStatusFail, report.Status)
+	checks := checksByName(report)
+	assert.Equal(t, models.HealthStatusOK, checks["database"].Status)
+	assert.Equal(t, models.HealthStatusFail, checks["schema"].Status)
+	assert.Equal(t, models.SchemaVersion{Current: 0, Expected: 1}, checks["schema"].Details)
+	assert.Equal(t, "schema version mismatch", checks["schema"].Error)
+}
+
+func TestHealthReadyFailsWhenDatabaseIsDown(t *testing.T) {
+	svc := service.NewHealthService(memory.New(), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := svc.Ready(ctx)
+
+	assert.Equal(t, models.HealthStatusFail, report.Status)
+	assert.Equal(t, models.HealthStatusFail, checksByName(report)["database"].Status)
+}
+
+func TestHealthReadyHidesCheckErrors(t *testing.T) {
+	svc := service.NewHealthService(unreachableStore{
+		Store: memory.New(),
+		err:   errors.New("dial tcp 10.0.0.5:5432: password authentication failed for user \"app\""),
+	}, nil)
+
+	report := svc.Ready(context.Background())
+
+	assert.Equal(t, models.HealthStatusFail, report.Status)
+	assert.Equal(t, "unreachable", checksByName(report)["database"].Error)
+}
+
+func TestHealthReadyReportsTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	svc := service.NewHealthService(unreachableStore{Store: memory.New()}, nil)
+
+	report := svc.Ready(ctx)
+
+	assert.Equal(t, "timeout", checksByName(report)["database"].Error)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+const (
+	DefaultIdempotencyTTL = 24 * time.Hour
+	// DefaultIdempotencyLease bounds how long a key stays pending when
+	// requests have no deadline.
+	DefaultIdempotencyLease = time.Minute
+	reserveAttempts         = 2
+)
+
+// IdempotencyService keeps a pending key for lease only, so a request that
+// never completes (the process crashed or panicked) frees its key soon; the
+// stored response is then kept for ttl.
+type IdempotencyService struct {
+	repo  Store
+	ttl   time.Duration
+	lease time.Duration
+}
+
+func NewIdempotencyService(repo Store, ttl, lease time.Duration) *IdempotencyService {
+	return &IdempotencyService{repo: repo, ttl: ttl, lease: lease}
+}
+
+// Begin claims key for a request with the given body hash. It returns a nil
+// record when the caller now owns the key and must call Complete or Release,
+// or the stored record when the request has already been answered.
+func (s *IdempotencyService) Begin(ctx context.Context, key, requestHash string) (*models.IdempotencyRecord, error) {
+	for attempt := 0; attempt < reserveAttempts; attempt++ {
+		reserved, err := s.repo.ReserveIdempotencyKey(ctx, key, requestHash, s.lease)
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return nil, nil
+		}
+
+		record, err := s.repo.GetIdempotencyRecord(ctx, key)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// The holder released the key between our insert and select.
+				continue
+			}
+			return nil, err
+		}
+
+		if record.RequestHash != requestHash {
+			return nil, ErrIdempotencyReused
+		}
+		if record.StatusCode == nil {
+			return nil, ErrIdempotencyPending
+		}
+
+		return record, nil
+	}
+
+	return nil, ErrIdempotencyPending
+}
+
+// Complete stores the response for key. headers is the JSON encoding of the
+// response headers to replay along with the body.
+func (s *IdempotencyService) Complete(ctx context.Context, key string, statusCode int, headers, body []byte) error {
+	return s.repo.CompleteIdempotencyKey(ctx, key, statusCode, headers, body, s.ttl)
+}
+
+func (s *IdempotencyService) Release(ctx context.Context, key string) error {
+	return s.repo.DeleteIdempotencyKey(ctx, key)
+}
+
+// PurgeExpired deletes keys whose TTL has passed and returns how many were
+// removed. Expired keys are also reclaimed lazily by Begin; purging keeps the
+// table from growing with keys that are never reused.
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.repo.PurgeExpiredIdempotencyKeys(ctx)
+}
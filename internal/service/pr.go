@@ -1,59 +1,64 @@
 package service
 
 import (
+	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"log/slog"
 	"math/rand"
 
+	"github.com/milyrock/PR-Reviewer/internal/metrics"
 	"github.com/milyrock/PR-Reviewer/internal/models"
 	"github.com/milyrock/PR-Reviewer/internal/repository"
 )
 
+const (
+	defaultPRListLimit = 50
+	maxPRListLimit     = 100
+)
+
 type PRService struct {
-	repo *repository.Repository
+	repo Store
 }
 
-func NewPRService(repo *repository.Repository) *PRService {
+func NewPRService(repo Store) *PRService {
 	return &PRService{repo: repo}
 }
 
-func (s *PRService) CreatePR(req models.CreatePRRequest) (*models.PullRequest, error) {
-	exists, err := s.repo.PRExists(req.PullRequestID)
-	if err != nil {
+func (s *PRService) CreatePR(ctx context.Context, req models.CreatePRRequest) (*models.PullRequest, error) {
+	if err := validateCreatePR(req); err != nil {
 		return nil, err
 	}
-	if exists {
-		return nil, ErrPRExists
+
+	pr := &models.PullRequest{
+		PullRequestID:   req.PullRequestID,
+		PullRequestName: req.PullRequestName,
+		AuthorID:        req.AuthorID,
+		Status:          "OPEN",
 	}
 
-	author, err := s.repo.GetUser(req.AuthorID)
+	err := s.repo.CreatePR(ctx, pr, func(author *models.User, candidates []models.User) ([]string, error) {
+		return selectReviewers(candidates, 2), nil
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrUserNotFound
 		}
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrPRExists
+		}
 		return nil, err
 	}
 
-	candidates, err := s.repo.GetActiveUsersByTeamName(author.TeamName, req.AuthorID)
-	if err != nil {
-		return nil, err
-	}
-
-	reviewerIDs := selectReviewers(candidates, 2)
+	reviewerIDs := pr.AssignedReviewers
 
-	pr := &models.PullRequest{
-		PullRequestID:     req.PullRequestID,
-		PullRequestName:   req.PullRequestName,
-		AuthorID:          req.AuthorID,
-		Status:            "OPEN",
-		AssignedReviewers: reviewerIDs,
-	}
-
-	if err := s.repo.CreatePR(pr, reviewerIDs); err != nil {
-		return nil, err
-	}
+	metrics.ReviewerAssignmentsTotal.Add(float64(len(reviewerIDs)))
+	metrics.ReviewersPerPR.Observe(float64(len(reviewerIDs)))
+	slog.InfoContext(ctx, "pull request created", "pr_id", pr.PullRequestID, "author_id", pr.AuthorID, "reviewers", reviewerIDs)
 
-	createdPR, err := s.repo.GetPR(req.PullRequestID)
+	createdPR, err := s.repo.GetPR(ctx, req.PullRequestID)
 	if err != nil {
 		return nil, err
 	}
@@ -61,8 +66,8 @@ func (s *PRService) CreatePR(req models.CreatePRRequest) (*models.PullRequest, e
 	return createdPR, nil
 }
 
-func (s *PRService) MergePR(req models.MergePRRequest) (*models.PullRequest, error) {
-	_, err := s.repo.GetPR(req.PullRequestID)
+func (s *PRService) GetPR(ctx context.Context, pullRequestID string) (*models.PullRequest, error) {
+	pr, err := s.repo.GetPR(ctx, pullRequestID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrPRNotFound
@@ -70,14 +75,24 @@ func (s *PRService) MergePR(req models.MergePRRequest) (*models.PullRequest, err
 		return nil, err
 	}
 
-	if err := s.repo.MergePR(req.PullRequestID); err != nil {
+	return pr, nil
+}
+
+func (s *PRService) MergePR(ctx context.Context, req models.MergePRRequest) (*models.PullRequest, error) {
+	if err := validateMergePR(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.MergePR(ctx, req.PullRequestID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrPRNotFound
 		}
 		return nil, err
 	}
 
-	pr, err := s.repo.GetPR(req.PullRequestID)
+	slog.InfoContext(ctx, "pull request merged", "pr_id", req.PullRequestID)
+
+	pr, err := s.repo.GetPR(ctx, req.PullRequestID)
 	if err != nil {
 		return nil, err
 	}
@@ -85,46 +100,88 @@ func (s *PRService) MergePR(req models.MergePRRequest) (*models.PullRequest, err
 	return pr, nil
 }
 
-func (s *PRService) ReassignPR(req models.ReassignPRRequest) (*models.PullRequest, string, error) {
-	pr, err := s.repo.GetPR(req.PullRequestID)
+func (s *PRService) ReassignPR(ctx context.Context, req models.ReassignPRRequest) (*models.PullRequest, string, error) {
+	if err := validateReassignPR(req); err != nil {
+		return nil, "", err
+	}
+
+	newReviewerID, err := s.repo.ReassignReviewer(ctx, req.PullRequestID, req.OldUserID, func(pr *models.PullRequest, candidates []models.User) (string, error) {
+		return chooseReplacement(pr, req.OldUserID, candidates)
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, "", ErrPRNotFound
 		}
+		if errors.Is(err, repository.ErrNotAssigned) {
+			return nil, "", ErrReviewerNotAssigned
+		}
 		return nil, "", err
 	}
 
-	if pr.Status == "MERGED" {
-		return nil, "", ErrPRMerged
-	}
+	metrics.ReviewerAssignmentsTotal.Inc()
+	metrics.ReviewerReassignmentsTotal.Inc()
+	slog.InfoContext(ctx, "reviewer reassigned", "pr_id", req.PullRequestID, "old_reviewer_id", req.OldUserID, "new_reviewer_id", newReviewerID)
 
-	isAssigned, err := s.repo.IsReviewerAssigned(req.PullRequestID, req.OldUserID)
+	updatedPR, err := s.repo.GetPR(ctx, req.PullRequestID)
 	if err != nil {
 		return nil, "", err
 	}
-	if !isAssigned {
-		return nil, "", ErrReviewerNotAssigned
+
+	return updatedPR, newReviewerID, nil
+}
+
+// ReviewPR records the decision of one of the PR's reviewers. A reviewer may
+// change their decision while the PR is open; latency statistics measure from
+// assignment to the first one.
+func (s *PRService) ReviewPR(ctx context.Context, req models.ReviewPRRequest) (*models.PullRequest, error) {
+	if err := validateReviewPR(req); err != nil {
+		return nil, err
+	}
+
+	err := s.repo.RecordReviewDecision(ctx, req.PullRequestID, req.ReviewerID, req.Decision)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
 	}
 
-	oldReviewer, err := s.repo.GetUser(req.OldUserID)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, "", ErrUserNotFound
+	pr, getErr := s.repo.GetPR(ctx, req.PullRequestID)
+	if getErr != nil {
+		if errors.Is(getErr, sql.ErrNoRows) {
+			return nil, ErrPRNotFound
 		}
-		return nil, "", err
+		return nil, getErr
 	}
 
-	candidates, err := s.repo.GetActiveUsersByTeamName(oldReviewer.TeamName, req.OldUserID)
+	// Nothing was recorded: tell the caller why from the current state.
 	if err != nil {
-		return nil, "", err
+		if pr.Status == "MERGED" {
+			return nil, ErrReviewMerged
+		}
+		return nil, ErrReviewerNotAssigned
+	}
+
+	slog.InfoContext(ctx, "review decision recorded", "pr_id", req.PullRequestID, "reviewer_id", req.ReviewerID, "decision", req.Decision)
+
+	return pr, nil
+}
+
+// chooseReplacement validates the reassignment against the locked PR and picks
+// a random active teammate of the old reviewer who is neither the author nor
+// already assigned.
+func chooseReplacement(pr *models.PullRequest, oldUserID string, candidates []models.User) (string, error) {
+	if pr.Status == "MERGED" {
+		return "", ErrPRMerged
 	}
 
-	filteredCandidates := []models.User{}
 	assignedMap := make(map[string]bool)
 	for _, reviewerID := range pr.AssignedReviewers {
 		assignedMap[reviewerID] = true
 	}
 
+	if !assignedMap[oldUserID] {
+		return "", ErrReviewerNotAssigned
+	}
+
+	filteredCandidates := []models.User{}
 	for _, candidate := range candidates {
 		if candidate.UserID != pr.AuthorID && !assignedMap[candidate.UserID] {
 			filteredCandidates = append(filteredCandidates, candidate)
@@ -132,21 +189,80 @@ func (s *PRService) ReassignPR(req models.ReassignPRRequest) (*models.PullReques
 	}
 
 	if len(filteredCandidates) == 0 {
-		return nil, "", ErrNoCandidate
+		metrics.NoCandidateTotal.Inc()
+		return "", ErrNoCandidate
 	}
 
-	newReviewer := filteredCandidates[rand.Intn(len(filteredCandidates))]
+	return filteredCandidates[rand.Intn(len(filteredCandidates))].UserID, nil
+}
 
-	if err := s.repo.ReassignReviewer(req.PullRequestID, req.OldUserID, newReviewer.UserID); err != nil {
-		return nil, "", err
+func (s *PRService) ListPRs(ctx context.Context, filter models.PRListFilter, cursor string) (*models.PRListResponse, error) {
+	if cursor != "" {
+		after, err := decodePRCursor(cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		filter.After = after
 	}
 
-	updatedPR, err := s.repo.GetPR(req.PullRequestID)
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPRListLimit
+	}
+	if filter.Limit > maxPRListLimit {
+		filter.Limit = maxPRListLimit
+	}
+
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	prs, err := s.repo.ListPRs(ctx, filter)
 	if err != nil {
-		return nil, "", err
+		return nil, err
+	}
+
+	resp := &models.PRListResponse{PullRequests: prs}
+	if resp.PullRequests == nil {
+		resp.PullRequests = []models.PullRequest{}
+	}
+
+	if len(prs) > limit {
+		resp.PullRequests = prs[:limit]
+		last := resp.PullRequests[limit-1]
+		if last.CreatedAt != nil {
+			resp.NextCursor = encodePRCursor(models.PRCursor{
+				CreatedAt:     *last.CreatedAt,
+				PullRequestID: last.PullRequestID,
+			})
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *PRService) ExportPRs(ctx context.Context, fn func(*models.PullRequest) error) error {
+	return s.repo.StreamPRs(ctx, fn)
+}
+
+func encodePRCursor(c models.PRCursor) string {
+	data, _ := json.Marshal(c) //nolint:errchkjson
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePRCursor(cursor string) (*models.PRCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var c models.PRCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if c.PullRequestID == "" || c.CreatedAt.IsZero() {
+		return nil, errors.New("incomplete cursor")
 	}
 
-	return updatedPR, newReviewer.UserID, nil
+	return &c, nil
 }
 
 func selectReviewers(candidates []models.User, maxCount int) []string {
@@ -0,0 +1,158 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository"
+	"github.com/milyrock/PR-Reviewer/internal/repository/memory"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPRService(t *testing.T, members ...models.TeamMember) *service.PRService {
+	t.Helper()
+	store := memory.New()
+	require.NoError(t, store.CreateTeam(context.Background(), "backend", members))
+	return service.NewPRService(store)
+}
+
+func TestCreatePRAssignsActiveTeammates(t *testing.T) {
+	ctx := context.Background()
+	svc := newPRService(t,
+		models.TeamMember{UserID: "u1", Username: "Alice", IsActive: true},
+		models.TeamMember{UserID: "u2", Username: "Bob", IsActive: true},
+		models.TeamMember{UserID: "u3", Username: "Charlie", IsActive: false},
+		models.TeamMember{UserID: "u4", Username: "David", IsActive: true},
+	)
+
+	pr, err := svc.CreatePR(ctx, models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "u1"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u2", "u4"}, pr.AssignedReviewers)
+
+	_, err = svc.CreatePR(ctx, models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "u2"})
+	assert.ErrorIs(t, err, service.ErrPRExists)
+
+	_, err = svc.CreatePR(ctx, models.CreatePRRequest{PullRequestID: "pr-2", PullRequestName: "Feature", AuthorID: "missing"})
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+}
+
+func TestReassignPR(t *testing.T) {
+	ctx := context.Background()
+	svc := newPRService(t,
+		models.TeamMember{UserID: "u1", Username: "Alice", IsActive: true},
+		models.TeamMember{UserID: "u2", Username: "Bob", IsActive: true},
+		models.TeamMember{UserID: "u3", Username: "Charlie", IsActive: true},
+		models.TeamMember{UserID: "u4", Username: "David", IsActive: true},
+	)
+
+	pr, err := svc.CreatePR(ctx, models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "u1"})
+	require.NoError(t, err)
+	require.Len(t, pr.AssignedReviewers, 2)
+
+	old := pr.AssignedReviewers[0]
+	updated, replacedBy, err := svc.ReassignPR(ctx, models.ReassignPRRequest{PullRequestID: "pr-1", OldUserID: old})
+	require.NoError(t, err)
+	assert.NotContains(t, updated.AssignedReviewers, old)
+	assert.NotContains(t, updated.AssignedReviewers, "u1")
+	assert.Contains(t, updated.AssignedReviewers, replacedBy)
+
+	_, _, err = svc.ReassignPR(ctx, models.ReassignPRRequest{PullRequestID: "pr-1", OldUserID: old})
+	assert.ErrorIs(t, err, service.ErrReviewerNotAssigned)
+
+	_, _, err = svc.ReassignPR(ctx, models.ReassignPRRequest{PullRequestID: "missing", OldUserID: old})
+	assert.ErrorIs(t, err, service.ErrPRNotFound)
+
+	_, err = svc.MergePR(ctx, models.MergePRRequest{PullRequestID: "pr-1"})
+	require.NoError(t, err)
+
+	_, _, err = svc.ReassignPR(ctx, models.ReassignPRRequest{PullRequestID: "pr-1", OldUserID: replacedBy})
+	assert.ErrorIs(t, err, service.ErrPRMerged)
+}
+
+func TestReassignPRWithoutCandidates(t *testing.T) {
+	ctx := context.Background()
+	svc := newPRService(t,
+		models.TeamMember{UserID: "u1", Username: "Alice", IsActive: true},
+		models.TeamMember{UserID: "u2", Username: "Bob", IsActive: true},
+	)
+
+	_, err := svc.CreatePR(ctx, models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "u1"})
+	require.NoError(t, err)
+
+	_, _, err = svc.ReassignPR(ctx, models.ReassignPRRequest{PullRequestID: "pr-1", OldUserID: "u2"})
+	assert.ErrorIs(t, err, service.ErrNoCandidate)
+}
+
+// unassignedStore reports that the reviewer was gone by the time the store
+// tried to remove it.
+type unassignedStore struct {
+	*memory.Store
+}
+
+func (unassignedStore) ReassignReviewer(context.Context, string, string, repository.ReassignChooser) (string, error) {
+	return "", repository.ErrNotAssigned
+}
+
+func TestReassignPRReviewerNoLongerAssigned(t *testing.T) {
+	svc := service.NewPRService(unassignedStore{Store: memory.New()})
+
+	_, _, err := svc.ReassignPR(context.Background(), models.ReassignPRRequest{PullRequestID: "pr-1", OldUserID: "u2"})
+	assert.ErrorIs(t, err, service.ErrReviewerNotAssigned)
+}
+
+func TestListPRsPagination(t *testing.T) {
+	ctx := context.Background()
+	svc := newPRService(t, models.TeamMember{UserID: "u1", Username: "Alice", IsActive: true})
+
+	for _, id := range []string{"pr-1", "pr-2", "pr-3"} {
+		_, err := svc.CreatePR(ctx, models.CreatePRRequest{PullRequestID: id, PullRequestName: id, AuthorID: "u1"})
+		require.NoError(t, err)
+	}
+
+	page, err := svc.ListPRs(ctx, models.PRListFilter{Limit: 2, SortAsc: true}, "")
+	require.NoError(t, err)
+	require.Len(t, page.PullRequests, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	next, err := svc.ListPRs(ctx, models.PRListFilter{Limit: 2, SortAsc: true}, page.NextCursor)
+	require.NoError(t, err)
+	require.Len(t, next.PullRequests, 1)
+	assert.Equal(t, "pr-3", next.PullRequests[0].PullRequestID)
+	assert.Empty(t, next.NextCursor)
+
+	_, err = svc.ListPRs(ctx, models.PRListFilter{}, "not-a-cursor")
+	assert.ErrorIs(t, err, service.ErrInvalidCursor)
+}
+
+func TestReviewPR(t *testing.T) {
+	ctx := context.Background()
+	svc := newPRService(t,
+		models.TeamMember{UserID: "u1", Username: "Alice", IsActive: true},
+		models.TeamMember{UserID: "u2", Username: "Bob", IsActive: true},
+	)
+
+	_, err := svc.CreatePR(ctx, models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "u1"})
+	require.NoError(t, err)
+
+	_, err = svc.ReviewPR(ctx, models.ReviewPRRequest{PullRequestID: "pr-1", ReviewerID: "u2", Decision: "LGTM"})
+	assert.Equal(t, []models.FieldError{{Field: "decision", Message: "must be APPROVED or CHANGES_REQUESTED"}}, fieldErrors(t, err))
+
+	_, err = svc.ReviewPR(ctx, models.ReviewPRRequest{PullRequestID: "missing", ReviewerID: "u2", Decision: models.DecisionApproved})
+	assert.ErrorIs(t, err, service.ErrPRNotFound)
+
+	_, err = svc.ReviewPR(ctx, models.ReviewPRRequest{PullRequestID: "pr-1", ReviewerID: "u1", Decision: models.DecisionApproved})
+	assert.ErrorIs(t, err, service.ErrReviewerNotAssigned)
+
+	pr, err := svc.ReviewPR(ctx, models.ReviewPRRequest{PullRequestID: "pr-1", ReviewerID: "u2", Decision: models.DecisionApproved})
+	require.NoError(t, err)
+	require.Len(t, pr.Reviewers, 1)
+	assert.Equal(t, models.DecisionApproved, *pr.Reviewers[0].Decision)
+
+	_, err = svc.MergePR(ctx, models.MergePRRequest{PullRequestID: "pr-1"})
+	require.NoError(t, err)
+	_, err = svc.ReviewPR(ctx, models.ReviewPRRequest{PullRequestID: "pr-1", ReviewerID: "u2", Decision: models.DecisionChangesRequested})
+	assert.ErrorIs(t, err, service.ErrReviewMerged)
+}
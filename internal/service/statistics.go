@@ -1,31 +1,339 @@
 package service
 
 import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
 	"github.com/milyrock/PR-Reviewer/internal/models"
 	"github.com/milyrock/PR-Reviewer/internal/repository"
 )
 
+const (
+	defaultFairnessWindow = 30 * 24 * time.Hour
+	fairnessExtremesCount = 3
+)
+
 type StatisticsService struct {
-	repo *repository.Repository
+	repo Store
 }
 
-func NewStatisticsService(repo *repository.Repository) *StatisticsService {
+func NewStatisticsService(repo Store) *StatisticsService {
 	return &StatisticsService{repo: repo}
 }
 
-func (s *StatisticsService) GetStatistics() (*models.StatisticsResponse, error) {
-	userStats, err := s.repo.GetUserReviewStats()
+func (s *StatisticsService) GetStatistics(ctx context.Context, filter models.StatisticsFilter) (*models.StatisticsResponse, error) {
+	userStats, err := s.repo.GetUserReviewStats(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	prStats, err := s.repo.GetPRReviewStats()
+	prStats, err := s.repo.GetPRReviewStats(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.StatisticsResponse{
+	if userStats == nil {
+		userStats = []models.UserReviewStats{}
+	}
+	if prStats == nil {
+		prStats = []models.PRReviewStats{}
+	}
+
+	resp := &models.StatisticsResponse{
 		UserStats: userStats,
 		PRStats:   prStats,
+	}
+
+	if filter.Bucket != "" {
+		resp.Timeline, err = s.repo.GetReviewTimeline(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *StatisticsService) GetLatency(ctx context.Context, filter models.StatisticsFilter) (*models.LatencyResponse, error) {
+	resp := &models.LatencyResponse{}
+
+	for metric, dst := range map[string]*[]models.LatencyStats{
+		repository.LatencyTimeToMergeByTeam:                   &resp.TimeToMergeByTeam,
+		repository.LatencyTimeToMergeByAuthor:                 &resp.TimeToMergeByAuthor,
+		repository.LatencyTimeToMergeByReviewer:               &resp.TimeToMergeByReviewer,
+		repository.LatencyTimeToMergeByWeek:                   &resp.TimeToMergeByWeek,
+		repository.LatencyAssignmentToMergeByReviewer:         &resp.AssignmentToMergeByReviewer,
+		repository.LatencyAssignmentToMergeByWeek:             &resp.AssignmentToMergeByWeek,
+		repository.LatencyAssignmentToFirstDecisionByReviewer: &resp.AssignmentToFirstDecisionByReviewer,
+		repository.LatencyAssignmentToFirstDecisionByWeek:     &resp.AssignmentToFirstDecisionByWeek,
+	} {
+		stats, err := s.repo.GetLatencyStats(ctx, metric, filter)
+		if err != nil {
+			return nil, err
+		}
+		if stats == nil {
+			stats = []models.LatencyStats{}
+		}
+		*dst = stats
+	}
+
+	return resp, nil
+}
+
+func (s *StatisticsService) GetFairness(ctx context.Context, filter models.StatisticsFilter) (*models.FairnessResponse, error) {
+	to := time.Now()
+	if filter.To != nil {
+		to = *filter.To
+	}
+	from := to.Add(-defaultFairnessWindow)
+	if filter.From != nil {
+		from = *filter.From
+	}
+
+	members, err := s.repo.GetActiveMemberAssignments(ctx, from, to, filter.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	byTeam := make(map[string][]models.MemberLoad)
+	var teamNames []string
+	for _, m := range members {
+		start := from
+		if m.CreatedAt.After(start) {
+			start = m.CreatedAt
+		}
+
+		days := to.Sub(start).Hours() / 24
+		if days < 1 {
+			days = 1
+		}
+
+		if _, ok := byTeam[m.TeamName]; !ok {
+			teamNames = append(teamNames, m.TeamName)
+		}
+		byTeam[m.TeamName] = append(byTeam[m.TeamName], models.MemberLoad{
+			UserID:            m.UserID,
+			Username:          m.Username,
+			Assignments:       m.Assignments,
+			DaysActive:        days,
+			AssignmentsPerDay: float64(m.Assignments) / days,
+		})
+	}
+
+	resp := &models.FairnessResponse{
+		From:  from,
+		To:    to,
+		Teams: make([]models.TeamFairness, 0, len(teamNames)),
+	}
+	for _, name := range teamNames {
+		resp.Teams = append(resp.Teams, teamFairness(name, byTeam[name]))
+	}
+
+	return resp, nil
+}
+
+func teamFairness(teamName string, loads []models.MemberLoad) models.TeamFairness {
+	counts := make([]float64, len(loads))
+	rates := make([]float64, len(loads))
+	total := 0
+	for i, load := range loads {
+		counts[i] = float64(load.Assignments)
+		rates[i] = load.AssignmentsPerDay
+		total += load.Assignments
+	}
+
+	ranked := make([]models.MemberLoad, len(loads))
+	copy(ranked, loads)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].AssignmentsPerDay > ranked[j].AssignmentsPerDay
+	})
+
+	// In a team of fewer than 2*fairnessExtremesCount members the lists
+	// would overlap; split the ranking instead, giving the odd member to the
+	// most loaded.
+	most := min(fairnessExtremesCount, (len(ranked)+1)/2)
+	leastCount := min(fairnessExtremesCount, len(ranked)-most)
+
+	least := make([]models.MemberLoad, 0, leastCount)
+	for i := len(ranked) - 1; i >= len(ranked)-leastCount; i-- {
+		least = append(least, ranked[i])
+	}
+
+	return models.TeamFairness{
+		TeamName:                teamName,
+		ActiveMembers:           len(loads),
+		TotalAssignments:        total,
+		MeanAssignments:         mean(counts),
+		MeanAssignmentsPerDay:   mean(rates),
+		StdDevAssignmentsPerDay: stdDev(rates),
+		Gini:                    gini(rates),
+		MostLoaded:              ranked[:most],
+		LeastLoaded:             least,
+		Members:                 loads,
+	}
+}
+
+func (s *StatisticsService) GetCollaborationGraph(ctx context.Context, filter models.StatisticsFilter) (*models.CollaborationGraph, error) {
+	edges, err := s.repo.GetReviewEdges(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if edges == nil {
+		edges = []models.GraphEdge{}
+	}
+
+	authors, err := s.repo.GetPRAuthors(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var userIDs []string
+	for _, id := range authors {
+		if !seen[id] {
+			seen[id] = true
+			userIDs = append(userIDs, id)
+		}
+	}
+	for _, edge := range edges {
+		for _, id := range []string{edge.AuthorID, edge.ReviewerID} {
+			if !seen[id] {
+				seen[id] = true
+				userIDs = append(userIDs, id)
+			}
+		}
+	}
+
+	nodes, err := s.repo.GetGraphNodes(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CollaborationGraph{
+		Nodes:       nodes,
+		Edges:       edges,
+		Reciprocity: reciprocity(edges),
+		BusFactor:   busFactor(nodes, edges, authors),
 	}, nil
 }
+
+// reciprocity returns the share of author->reviewer edges for which the
+// reviewer->author edge also exists.
+func reciprocity(edges []models.GraphEdge) float64 {
+	if len(edges) == 0 {
+		return 0
+	}
+
+	exists := make(map[[2]string]bool, len(edges))
+	for _, edge := range edges {
+		exists[[2]string{edge.AuthorID, edge.ReviewerID}] = true
+	}
+
+	mutual := 0
+	for _, edge := range edges {
+		if exists[[2]string{edge.ReviewerID, edge.AuthorID}] {
+			mutual++
+		}
+	}
+
+	return float64(mutual) / float64(len(edges))
+}
+
+// busFactor counts, for every author, how many distinct members have reviewed
+// their work, and summarises it per author team. Authors whose PRs never got
+// a reviewer count as 0.
+func busFactor(nodes []models.GraphNode, edges []models.GraphEdge, authors []string) []models.TeamBusFactor {
+	teamOf := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		teamOf[node.UserID] = node.TeamName
+	}
+
+	reviewers := make(map[string]int)
+	for _, edge := range edges {
+		reviewers[edge.AuthorID]++
+	}
+
+	byTeam := make(map[string]*models.TeamBusFactor)
+	var teamNames []string
+	for _, author := range authors {
+		team := teamOf[author]
+		tbf, ok := byTeam[team]
+		if !ok {
+			tbf = &models.TeamBusFactor{TeamName: team, MinBusFactor: reviewers[author]}
+			byTeam[team] = tbf
+			teamNames = append(teamNames, team)
+		}
+
+		tbf.Authors = append(tbf.Authors, models.AuthorBusFactor{AuthorID: author, Reviewers: reviewers[author]})
+		if reviewers[author] < tbf.MinBusFactor {
+			tbf.MinBusFactor = reviewers[author]
+		}
+	}
+
+	sort.Strings(teamNames)
+	result := make([]models.TeamBusFactor, 0, len(teamNames))
+	for _, name := range teamNames {
+		tbf := byTeam[name]
+		total := 0
+		for _, author := range tbf.Authors {
+			total += author.Reviewers
+		}
+		tbf.AvgBusFactor = float64(total) / float64(len(tbf.Authors))
+		result = append(result, *tbf)
+	}
+
+	return result
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+
+	return sum / float64(len(values))
+}
+
+func stdDev(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	m := mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+
+	return math.Sqrt(sum / float64(len(values)))
+}
+
+// gini returns the Gini coefficient of values: 0 when every member has the same
+// load, approaching 1 when a single member takes all assignments.
+func gini(values []float64) float64 {
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, n)
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var sum, weighted float64
+	for i, v := range sorted {
+		sum += v
+		weighted += float64(i+1) * v
+	}
+	if sum == 0 {
+		return 0
+	}
+
+	return 2*weighted/(float64(n)*sum) - float64(n+1)/float64(n)
+}
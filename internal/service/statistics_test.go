@@ -0,0 +1,137 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGini(t *testing.T) {
+	assert.Equal(t, 0.0, gini(nil))
+	assert.Equal(t, 0.0, gini([]float64{0, 0, 0}))
+	assert.InDelta(t, 0.0, gini([]float64{4, 4, 4, 4}), 1e-9)
+	assert.InDelta(t, 0.75, gini([]float64{0, 0, 0, 8}), 1e-9)
+	assert.InDelta(t, 0.25, gini([]float64{1, 3}), 1e-9)
+}
+
+func TestStdDev(t *testing.T) {
+	assert.Equal(t, 0.0, stdDev(nil))
+	assert.InDelta(t, 2.0, stdDev([]float64{2, 4, 4, 4, 5, 5, 7, 9}), 1e-9)
+}
+
+func TestTeamFairnessExtremes(t *testing.T) {
+	loads := []models.MemberLoad{
+		{UserID: "u1", Assignments: 1, AssignmentsPerDay: 0.1},
+		{UserID: "u2", Assignments: 9, AssignmentsPerDay: 0.9},
+		{UserID: "u3", Assignments: 5, AssignmentsPerDay: 0.5},
+		{UserID: "u4", Assignments: 0, AssignmentsPerDay: 0},
+	}
+
+	fairness := teamFairness("backend", loads)
+
+	assert.Equal(t, 4, fairness.ActiveMembers)
+	assert.Equal(t, 15, fairness.TotalAssignments)
+	assert.InDelta(t, 3.75, fairness.MeanAssignments, 1e-9)
+	assert.Equal(t, "u2", fairness.MostLoaded[0].UserID)
+	assert.Equal(t, "u4", fairness.LeastLoaded[0].UserID)
+	assert.Len(t, fairness.Members, 4)
+}
+
+func TestTeamFairnessExtremesDoNotOverlap(t *testing.T) {
+	tests := []struct {
+		members     int
+		most, least []string
+	}{
+		{members: 1, most: []string{"u1"}},
+		{members: 2, most: []string{"u1"}, least: []string{"u2"}},
+		{members: 3, most: []string{"u1", "u2"}, least: []string{"u3"}},
+		{members: 5, most: []string{"u1", "u2", "u3"}, least: []string{"u5", "u4"}},
+		{members: 7, most: []string{"u1", "u2", "u3"}, least: []string{"u7", "u6", "u5"}},
+	}
+
+	for _, tt := range tests {
+		loads := make([]models.MemberLoad, tt.members)
+		for i := range loads {
+			loads[i] = models.MemberLoad{UserID: fmt.Sprintf("u%d", i+1), AssignmentsPerDay: float64(tt.members - i)}
+		}
+
+		fairness := teamFairness("backend", loads)
+
+		var most, least []string
+		for _, load := range fairness.MostLoaded {
+			most = append(most, load.UserID)
+		}
+		for _, load := range fairness.LeastLoaded {
+			least = append(least, load.UserID)
+		}
+		assert.Equal(t, tt.most, most, "%d members", tt.members)
+		assert.Equal(t, tt.least, least, "%d members", tt.members)
+	}
+}
+
+func TestTeamFairnessNormalisesByDaysActive(t *testing.T) {
+	// u2 joined a third of the way into the window and reviewed at the same
+	// rate as u1, so the load is even despite the different counts.
+	loads := []models.MemberLoad{
+		{UserID: "u1", Assignments: 30, DaysActive: 30, AssignmentsPerDay: 1},
+		{UserID: "u2", Assignments: 20, DaysActive: 20, AssignmentsPerDay: 1},
+	}
+
+	fairness := teamFairness("backend", loads)
+
+	assert.InDelta(t, 25.0, fairness.MeanAssignments, 1e-9)
+	assert.InDelta(t, 1.0, fairness.MeanAssignmentsPerDay, 1e-9)
+	assert.InDelta(t, 0.0, fairness.StdDevAssignmentsPerDay, 1e-9)
+	assert.InDelta(t, 0.0, fairness.Gini, 1e-9)
+}
+
+func TestReciprocityAndBusFactor(t *testing.T) {
+	nodes := []models.GraphNode{
+		{UserID: "u1", TeamName: "backend"},
+		{UserID: "u2", TeamName: "backend"},
+		{UserID: "u3", TeamName: "backend"},
+		{UserID: "u4", TeamName: "frontend"},
+	}
+	edges := []models.GraphEdge{
+		{AuthorID: "u1", ReviewerID: "u2", Weight: 3},
+		{AuthorID: "u1", ReviewerID: "u3", Weight: 1},
+		{AuthorID: "u2", ReviewerID: "u1", Weight: 2},
+		{AuthorID: "u4", ReviewerID: "u3", Weight: 1},
+	}
+
+	assert.InDelta(t, 0.5, reciprocity(edges), 1e-9)
+	assert.Equal(t, 0.0, reciprocity(nil))
+
+	factors := busFactor(nodes, edges, []string{"u1", "u2", "u4"})
+	assert.Len(t, factors, 2)
+	assert.Equal(t, "backend", factors[0].TeamName)
+	assert.Equal(t, 1, factors[0].MinBusFactor)
+	assert.InDelta(t, 1.5, factors[0].AvgBusFactor, 1e-9)
+	assert.Equal(t, "frontend", factors[1].TeamName)
+	assert.Equal(t, 1, factors[1].MinBusFactor)
+}
+
+func TestBusFactorCountsAuthorsWithoutReviewers(t *testing.T) {
+	nodes := []models.GraphNode{
+		{UserID: "u1", TeamName: "backend"},
+		{UserID: "u2", TeamName: "backend"},
+		{UserID: "u3", TeamName: "backend"},
+	}
+	edges := []models.GraphEdge{
+		{AuthorID: "u1", ReviewerID: "u2", Weight: 1},
+		{AuthorID: "u1", ReviewerID: "u3", Weight: 1},
+	}
+
+	// u2 authored a PR that never got a reviewer.
+	factors := busFactor(nodes, edges, []string{"u1", "u2"})
+	require.Len(t, factors, 1)
+	assert.Equal(t, 0, factors[0].MinBusFactor)
+	assert.InDelta(t, 1.0, factors[0].AvgBusFactor, 1e-9)
+	assert.Equal(t, []models.AuthorBusFactor{
+		{AuthorID: "u1", Reviewers: 2},
+		{AuthorID: "u2", Reviewers: 0},
+	}, factors[0].Authors)
+}
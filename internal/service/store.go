@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository"
+)
+
+// Store is the persistence layer the services depend on. *repository.Repository
+// implements it on top of Postgres and memory.Store keeps everything in
+// process. Missing rows are reported as sql.ErrNoRows and duplicate inserts as
+// repository.ErrDuplicate by every implementation.
+type Store interface {
+	CreateTeam(ctx context.Context, teamName string, members []models.TeamMember) error
+	TeamExists(ctx context.Context, teamName string) (bool, error)
+	GetTeam(ctx context.Context, teamName string) (*models.Team, error)
+
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+	SetUserIsActive(ctx context.Context, userID string, isActive bool) error
+	GetActiveUsersByTeamName(ctx context.Context, teamName string, excludeUserID string) ([]models.User, error)
+	GetUserReviewPRs(ctx context.Context, userID string) ([]models.PullRequestShort, error)
+	GetUserOpenReviewPRs(ctx context.Context, userID string) ([]models.PullRequestShort, error)
+	GetActiveMemberAssignments(ctx context.Context, from, to time.Time, teamName string) ([]models.MemberAssignments, error)
+	GetUserReviewStats(ctx context.Context, filter models.StatisticsFilter) ([]models.UserReviewStats, error)
+
+	PRExists(ctx context.Context, pullRequestID string) (bool, error)
+	GetPR(ctx context.Context, pullRequestID string) (*models.PullRequest, error)
+	CreatePR(ctx context.Context, pr *models.PullRequest, choose repository.CreateChooser) error
+	MergePR(ctx context.Context, pullRequestID string) error
+	ReassignReviewer(ctx context.Context, pullRequestID, oldUserID string, choose repository.ReassignChooser) (string, error)
+	RecordReviewDecision(ctx context.Context, pullRequestID, userID, decision string) error
+	ListPRs(ctx context.Context, filter models.PRListFilter) ([]models.PullRequest, error)
+	GetUserRecentlyMergedPRs(ctx context.Context, userID string, since time.Time, limit int) ([]models.PullRequest, error)
+	StreamPRs(ctx context.Context, fn func(*models.PullRequest) error) error
+	CountOpenPRsByTeam(ctx context.Context) (map[string]int, error)
+	GetPRReviewStats(ctx context.Context, filter models.StatisticsFilter) ([]models.PRReviewStats, error)
+
+	GetReviewTimeline(ctx context.Context, filter models.StatisticsFilter) ([]models.StatisticsBucket, error)
+	GetLatencyStats(ctx context.Context, metric string, filter models.StatisticsFilter) ([]models.LatencyStats, error)
+	GetReviewEdges(ctx context.Context, filter models.StatisticsFilter) ([]models.GraphEdge, error)
+	GetPRAuthors(ctx context.Context, filter models.StatisticsFilter) ([]string, error)
+	GetGraphNodes(ctx context.Context, userIDs []string) ([]models.GraphNode, error)
+
+	CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID string) error
+
+	ReserveIdempotencyKey(ctx context.Context, key, requestHash string, lease time.Duration) (bool, error)
+	GetIdempotencyRecord(ctx context.Context, key string) (*models.IdempotencyRecord, error)
+	CompleteIdempotencyKey(ctx context.Context, key string, statusCode int, headers, body []byte, ttl time.Duration) error
+	DeleteIdempotencyKey(ctx context.Context, key string) error
+	PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+
+	Ping(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (models.SchemaVersion, error)
+}
+
+var _ Store = (*repository.Repository)(nil)
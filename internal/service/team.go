@@ -1,23 +1,28 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"log/slog"
 
 	"github.com/milyrock/PR-Reviewer/internal/models"
-	"github.com/milyrock/PR-Reviewer/internal/repository"
 )
 
 type TeamService struct {
-	repo *repository.Repository
+	repo Store
 }
 
-func NewTeamService(repo *repository.Repository) *TeamService {
+func NewTeamService(repo Store) *TeamService {
 	return &TeamService{repo: repo}
 }
 
-func (s *TeamService) AddTeam(req models.CreateTeamRequest) (*models.Team, error) {
-	exists, err := s.repo.TeamExists(req.TeamName)
+func (s *TeamService) AddTeam(ctx context.Context, req models.CreateTeamRequest) (*models.Team, error) {
+	if err := validateCreateTeam(req); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.repo.TeamExists(ctx, req.TeamName)
 	if err != nil {
 		return nil, err
 	}
@@ -25,26 +30,34 @@ func (s *TeamService) AddTeam(req models.CreateTeamRequest) (*models.Team, error
 		return nil, ErrTeamExists
 	}
 
-	if err := s.repo.CreateTeam(req.TeamName, req.Members); err != nil {
+	if err := s.repo.CreateTeam(ctx, req.TeamName, req.Members); err != nil {
 		return nil, err
 	}
 
-	team, err := s.repo.GetTeam(req.TeamName)
+	slog.InfoContext(ctx, "team created", "team_name", req.TeamName, "members", len(req.Members))
+
+	team, err := s.repo.GetTeam(ctx, req.TeamName)
 	if err != nil {
 		return nil, err
 	}
+	if team.Members == nil {
+		team.Members = []models.TeamMember{}
+	}
 
 	return team, nil
 }
 
-func (s *TeamService) GetTeam(teamName string) (*models.Team, error) {
-	team, err := s.repo.GetTeam(teamName)
+func (s *TeamService) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
+	team, err := s.repo.GetTeam(ctx, teamName)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrTeamNotFound
 		}
 		return nil, err
 	}
+	if team.Members == nil {
+		team.Members = []models.TeamMember{}
+	}
 
 	return team, nil
 }
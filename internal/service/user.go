@@ -1,30 +1,44 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"log/slog"
+	"time"
 
 	"github.com/milyrock/PR-Reviewer/internal/models"
-	"github.com/milyrock/PR-Reviewer/internal/repository"
+)
+
+const (
+	dashboardAuthoredLimit = 50
+	dashboardMergedLimit   = 20
+	dashboardMergedWindow  = 14 * 24 * time.Hour
 )
 
 type UserService struct {
-	repo *repository.Repository
+	repo Store
 }
 
-func NewUserService(repo *repository.Repository) *UserService {
+func NewUserService(repo Store) *UserService {
 	return &UserService{repo: repo}
 }
 
-func (s *UserService) SetIsActive(req models.SetIsActiveRequest) (*models.User, error) {
-	if err := s.repo.SetUserIsActive(req.UserID, req.IsActive); err != nil {
+func (s *UserService) SetIsActive(ctx context.Context, req models.SetIsActiveRequest) (*models.User, error) {
+	if err := validateSetIsActive(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetUserIsActive(ctx, req.UserID, req.IsActive); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
 
-	user, err := s.repo.GetUser(req.UserID)
+	slog.InfoContext(ctx, "user activity changed", "user_id", req.UserID, "is_active", req.IsActive)
+
+	user, err := s.repo.GetUser(ctx, req.UserID)
 	if err != nil {
 		return nil, err
 	}
@@ -32,8 +46,8 @@ func (s *UserService) SetIsActive(req models.SetIsActiveRequest) (*models.User,
 	return user, nil
 }
 
-func (s *UserService) GetReview(userID string) ([]models.PullRequestShort, error) {
-	_, err := s.repo.GetUser(userID)
+func (s *UserService) GetReview(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
+	_, err := s.repo.GetUser(ctx, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrUserNotFound
@@ -41,10 +55,60 @@ func (s *UserService) GetReview(userID string) ([]models.PullRequestShort, error
 		return nil, err
 	}
 
-	prs, err := s.repo.GetUserReviewPRs(userID)
+	prs, err := s.repo.GetUserReviewPRs(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	if prs == nil {
+		prs = []models.PullRequestShort{}
+	}
 
 	return prs, nil
 }
+
+func (s *UserService) GetDashboard(ctx context.Context, userID string) (*models.UserDashboard, error) {
+	_, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	toReview, err := s.repo.GetUserOpenReviewPRs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	authored, err := s.repo.ListPRs(ctx, models.PRListFilter{
+		AuthorID: userID,
+		Status:   "OPEN",
+		Limit:    dashboardAuthoredLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := s.repo.GetUserRecentlyMergedPRs(ctx, userID, time.Now().Add(-dashboardMergedWindow), dashboardMergedLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &models.UserDashboard{
+		UserID:         userID,
+		ToReview:       toReview,
+		Authored:       authored,
+		RecentlyMerged: merged,
+	}
+	if dashboard.ToReview == nil {
+		dashboard.ToReview = []models.PullRequestShort{}
+	}
+	if dashboard.Authored == nil {
+		dashboard.Authored = []models.PullRequest{}
+	}
+	if dashboard.RecentlyMerged == nil {
+		dashboard.RecentlyMerged = []models.PullRequest{}
+	}
+
+	return dashboard, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+)
+
+// Length limits of the VARCHAR columns in db/pr.sql. They are checked before
+// the request reaches the store so that an oversized value is reported as a
+// field error instead of failing the insert.
+const (
+	maxTeamNameLength   = 100
+	maxUserIDLength     = 50
+	maxUsernameLength   = 100
+	maxPRIDLength       = 50
+	maxPRNameLength     = 200
+	maxAPIKeyNameLength = 100
+)
+
+type fieldErrors []models.FieldError
+
+func (e *fieldErrors) add(field, message string) {
+	*e = append(*e, models.FieldError{Field: field, Message: message})
+}
+
+// required checks a mandatory string field: not blank and at most max
+// characters.
+func (e *fieldErrors) required(field, value string, max int) {
+	if strings.TrimSpace(value) == "" {
+		e.add(field, "is required")
+		return
+	}
+	e.maxLength(field, value, max)
+}
+
+func (e *fieldErrors) maxLength(field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		e.add(field, fmt.Sprintf("must be at most %d characters", max))
+	}
+}
+
+func (e fieldErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return NewValidationError(e...)
+}
+
+func validateCreateTeam(req models.CreateTeamRequest) error {
+	var errs fieldErrors
+	errs.required("team_name", req.TeamName, maxTeamNameLength)
+
+	seen := make(map[string]bool, len(req.Members))
+	for i, member := range req.Members {
+		prefix := fmt.Sprintf("members[%d].", i)
+		errs.required(prefix+"user_id", member.UserID, maxUserIDLength)
+		errs.required(prefix+"username", member.Username, maxUsernameLength)
+
+		if member.UserID != "" && seen[member.UserID] {
+			errs.add(prefix+"user_id", "is duplicated")
+		}
+		seen[member.UserID] = true
+	}
+
+	return errs.err()
+}
+
+func validateSetIsActive(req models.SetIsActiveRequest) error {
+	var errs fieldErrors
+	errs.required("user_id", req.UserID, maxUserIDLength)
+	return errs.err()
+}
+
+func validateCreatePR(req models.CreatePRRequest) error {
+	var errs fieldErrors
+	errs.required("pull_request_id", req.PullRequestID, maxPRIDLength)
+	errs.required("pull_request_name", req.PullRequestName, maxPRNameLength)
+	errs.required("author_id", req.AuthorID, maxUserIDLength)
+	return errs.err()
+}
+
+func validateMergePR(req models.MergePRRequest) error {
+	var errs fieldErrors
+	errs.required("pull_request_id", req.PullRequestID, maxPRIDLength)
+	return errs.err()
+}
+
+func validateReassignPR(req models.ReassignPRRequest) error {
+	var errs fieldErrors
+	errs.required("pull_request_id", req.PullRequestID, maxPRIDLength)
+	errs.required("old_reviewer_id", req.OldUserID, maxUserIDLength)
+	return errs.err()
+}
+
+func validateReviewPR(req models.ReviewPRRequest) error {
+	var errs fieldErrors
+	errs.required("pull_request_id", req.PullRequestID, maxPRIDLength)
+	errs.required("reviewer_id", req.ReviewerID, maxUserIDLength)
+	if req.Decision != models.DecisionApproved && req.Decision != models.DecisionChangesRequested {
+		errs.add("decision", "must be APPROVED or CHANGES_REQUESTED")
+	}
+	return errs.err()
+}
+
+func validateCreateAPIKey(req models.CreateAPIKeyRequest) error {
+	var errs fieldErrors
+	errs.maxLength("name", req.Name, maxAPIKeyNameLength)
+	if !validRole(req.Role) {
+		errs.add("role", "must be admin, integration or read_only")
+	}
+	return errs.err()
+}
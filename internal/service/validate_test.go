@@ -0,0 +1,72 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository/memory"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fieldErrors(t *testing.T, err error) []models.FieldError {
+	t.Helper()
+	var domainErr *service.Error
+	require.True(t, errors.As(err, &domainErr), "expected a domain error, got %v", err)
+	assert.Equal(t, "INVALID_REQUEST", domainErr.Code)
+	return domainErr.Details
+}
+
+func TestAddTeamValidation(t *testing.T) {
+	svc := service.NewTeamService(memory.New())
+
+	_, err := svc.AddTeam(context.Background(), models.CreateTeamRequest{
+		TeamName: strings.Repeat("t", 101),
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice"},
+			{UserID: " ", Username: "Bob"},
+			{UserID: "u1", Username: ""},
+		},
+	})
+
+	assert.Equal(t, []models.FieldError{
+		{Field: "team_name", Message: "must be at most 100 characters"},
+		{Field: "members[1].user_id", Message: "is required"},
+		{Field: "members[2].username", Message: "is required"},
+		{Field: "members[2].user_id", Message: "is duplicated"},
+	}, fieldErrors(t, err))
+}
+
+func TestPRValidation(t *testing.T) {
+	ctx := context.Background()
+	svc := service.NewPRService(memory.New())
+
+	_, err := svc.CreatePR(ctx, models.CreatePRRequest{PullRequestName: "Feature", AuthorID: strings.Repeat("ю", 51)})
+	assert.Equal(t, []models.FieldError{
+		{Field: "pull_request_id", Message: "is required"},
+		{Field: "author_id", Message: "must be at most 50 characters"},
+	}, fieldErrors(t, err))
+
+	// Limits count characters, as VARCHAR does, not bytes.
+	_, err = svc.CreatePR(ctx, models.CreatePRRequest{PullRequestID: strings.Repeat("ю", 50), PullRequestName: "Feature", AuthorID: "u1"})
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+
+	_, err = svc.MergePR(ctx, models.MergePRRequest{})
+	assert.Equal(t, []models.FieldError{{Field: "pull_request_id", Message: "is required"}}, fieldErrors(t, err))
+
+	_, _, err = svc.ReassignPR(ctx, models.ReassignPRRequest{PullRequestID: "pr-1"})
+	assert.Equal(t, []models.FieldError{{Field: "old_reviewer_id", Message: "is required"}}, fieldErrors(t, err))
+}
+
+func TestNotFoundCodesAreDistinct(t *testing.T) {
+	codes := map[string]bool{}
+	for _, err := range []*service.Error{service.ErrPRNotFound, service.ErrUserNotFound, service.ErrTeamNotFound} {
+		assert.Equal(t, 404, err.Status)
+		codes[err.Code] = true
+	}
+	assert.Len(t, codes, 3)
+}
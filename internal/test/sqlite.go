@@ -0,0 +1,25 @@
+package test
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/milyrock/PR-Reviewer/internal/repository/sqlite"
+)
+
+// SetupSQLiteDB opens a SQLite database at path and applies db/sqlite.sql.
+// Unlike SetupTestDB it needs no Docker.
+func SetupSQLiteDB(path string) (*sqlx.DB, error) {
+	db, err := sqlite.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := applyMigrations(db, "db/sqlite.sql"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	return db, nil
+}
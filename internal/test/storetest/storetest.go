@@ -0,0 +1,794 @@
+// Package storetest is a conformance suite for service.Store implementations.
+// Every implementation must pass it so that services behave the same whichever
+// backend they run on.
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/milyrock/PR-Reviewer/internal/models"
+	"github.com/milyrock/PR-Reviewer/internal/repository"
+	"github.com/milyrock/PR-Reviewer/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run executes the suite. newStore must return an empty store for every call.
+func Run(t *testing.T, newStore func(t *testing.T) service.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store service.Store)
+	}{
+		{"Teams", testTeams},
+		{"Users", testUsers},
+		{"CreatePR", testCreatePR},
+		{"MergePR", testMergePR},
+		{"ReassignReviewer", testReassignReviewer},
+		{"ReviewDecisions", testReviewDecisions},
+		{"ListPRs", testListPRs},
+		{"UserPRs", testUserPRs},
+		{"StreamPRs", testStreamPRs},
+		{"Statistics", testStatistics},
+		{"Latency", testLatency},
+		{"Graph", testGraph},
+		{"APIKeys", testAPIKeys},
+		{"Idempotency", testIdempotency},
+		{"CancelledContext", testCancelledContext},
+		{"Health", testHealth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+func seedTeam(t *testing.T, store service.Store, teamName string, members ...models.TeamMember) {
+	t.Helper()
+	require.NoError(t, store.CreateTeam(context.Background(), teamName, members))
+}
+
+func member(id string, active bool) models.TeamMember {
+	return models.TeamMember{UserID: id, Username: "user " + id, IsActive: active}
+}
+
+func seedPR(t *testing.T, store service.Store, id, authorID string, reviewerIDs ...string) {
+	t.Helper()
+	pr := &models.PullRequest{
+		PullRequestID:   id,
+		PullRequestName: "PR " + id,
+		AuthorID:        authorID,
+		Status:          "OPEN",
+	}
+	require.NoError(t, store.CreatePR(context.Background(), pr, chooseReviewers(reviewerIDs...)))
+}
+
+func chooseReviewers(ids ...string) repository.CreateChooser {
+	return func(*models.User, []models.User) ([]string, error) {
+		return ids, nil
+	}
+}
+
+func testTeams(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	exists, err := store.TeamExists(ctx, "backend")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = store.GetTeam(ctx, "backend")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	seedTeam(t, store, "backend", member("u2", true), member("u1", false))
+
+	exists, err = store.TeamExists(ctx, "backend")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	team, err := store.GetTeam(ctx, "backend")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", team.TeamName)
+	assert.Equal(t, []models.TeamMember{member("u1", false), member("u2", true)}, team.Members)
+
+	// Re-adding a user moves it to the new team and updates its fields.
+	seedTeam(t, store, "frontend", models.TeamMember{UserID: "u1", Username: "Alice", IsActive: true})
+
+	team, err = store.GetTeam(ctx, "backend")
+	require.NoError(t, err)
+	assert.Equal(t, []models.TeamMember{member("u2", true)}, team.Members)
+
+	user, err := store.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, models.User{UserID: "u1", Username: "Alice", TeamName: "frontend", IsActive: true}, *user)
+
+	seedTeam(t, store, "empty")
+	team, err = store.GetTeam(ctx, "empty")
+	require.NoError(t, err)
+	assert.Empty(t, team.Members)
+}
+
+func testUsers(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	seedTeam(t, store, "backend", member("u1", true), member("u2", true), member("u3", false), member("u4", true))
+
+	_, err := store.GetUser(ctx, "missing")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.ErrorIs(t, store.SetUserIsActive(ctx, "missing", true), sql.ErrNoRows)
+
+	users, err := store.GetActiveUsersByTeamName(ctx, "backend", "u2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u4"}, userIDs(users))
+
+	require.NoError(t, store.SetUserIsActive(ctx, "u3", true))
+	require.NoError(t, store.SetUserIsActive(ctx, "u4", false))
+
+	users, err = store.GetActiveUsersByTeamName(ctx, "backend", "u2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u3"}, userIDs(users))
+}
+
+func userIDs(users []models.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.UserID)
+	}
+	return ids
+}
+
+func testCreatePR(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	seedTeam(t, store, "backend", member("u1", true), member("u2", true), member("u3", true))
+
+	exists, err := store.PRExists(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = store.GetPR(ctx, "pr-1")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	seedPR(t, store, "pr-1", "u1", "u3", "u2")
+
+	exists, err = store.PRExists(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	pr, err := store.GetPR(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "PR pr-1", pr.PullRequestName)
+	assert.Equal(t, "u1", pr.AuthorID)
+	assert.Equal(t, "OPEN", pr.Status)
+	assert.Equal(t, []string{"u2", "u3"}, pr.AssignedReviewers)
+	require.Len(t, pr.Reviewers, 2)
+	assert.Equal(t, "user u2", pr.Reviewers[0].Username)
+	assert.Equal(t, "backend", pr.Reviewers[0].TeamName)
+	require.NotNil(t, pr.CreatedAt)
+	require.NotNil(t, pr.UpdatedAt)
+	assert.Nil(t, pr.MergedAt)
+	assert.True(t, pr.CreatedAt.Equal(*pr.UpdatedAt))
+
+	err = store.CreatePR(ctx, &models.PullRequest{PullRequestID: "pr-1", PullRequestName: "again", AuthorID: "u2", Status: "OPEN"}, chooseReviewers())
+	assert.ErrorIs(t, err, repository.ErrDuplicate)
+
+	err = store.CreatePR(ctx, &models.PullRequest{PullRequestID: "pr-3", PullRequestName: "orphan", AuthorID: "missing", Status: "OPEN"}, func(*models.User, []models.User) ([]string, error) {
+		t.Fatal("chooser must not be called for a missing author")
+		return nil, nil
+	})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// The chooser sees the author and their active teammates; its choice is
+	// stored and reported back.
+	seedTeam(t, store, "frontend", member("f1", true), member("f2", true), member("f3", false))
+	var (
+		seenAuthor     *models.User
+		seenCandidates []models.User
+	)
+	pr3 := &models.PullRequest{PullRequestID: "pr-3", PullRequestName: "PR pr-3", AuthorID: "f1", Status: "OPEN"}
+	require.NoError(t, store.CreatePR(ctx, pr3, func(author *models.User, candidates []models.User) ([]string, error) {
+		seenAuthor, seenCandidates = author, candidates
+		return []string{"f2"}, nil
+	}))
+	assert.Equal(t, "frontend", seenAuthor.TeamName)
+	assert.Equal(t, []string{"f2"}, userIDs(seenCandidates))
+	assert.Equal(t, []string{"f2"}, pr3.AssignedReviewers)
+
+	// A chooser error aborts the insert.
+	errNoReviewers := errors.New("no reviewers")
+	err = store.CreatePR(ctx, &models.PullRequest{PullRequestID: "pr-4", PullRequestName: "PR pr-4", AuthorID: "f1", Status: "OPEN"}, func(*models.User, []models.User) ([]string, error) {
+		return nil, errNoReviewers
+	})
+	assert.ErrorIs(t, err, errNoReviewers)
+	_, err = store.GetPR(ctx, "pr-4")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	seedPR(t, store, "pr-2", "u1")
+	pr, err = store.GetPR(ctx, "pr-2")
+	require.NoError(t, err)
+	assert.Empty(t, pr.AssignedReviewers)
+	assert.NotNil(t, pr.AssignedReviewers)
+}
+
+func testMergePR(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	seedTeam(t, store, "backend", member("u1", true), member("u2", true))
+	seedPR(t, store, "pr-1", "u1", "u2")
+
+	assert.ErrorIs(t, store.MergePR(ctx, "missing"), sql.ErrNoRows)
+
+	require.NoError(t, store.MergePR(ctx, "pr-1"))
+	pr, err := store.GetPR(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "MERGED", pr.Status)
+	require.NotNil(t, pr.MergedAt)
+	mergedAt := *pr.MergedAt
+
+	// Merging again keeps the original timestamp.
+	require.NoError(t, store.MergePR(ctx, "pr-1"))
+	pr, err = store.GetPR(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.True(t, mergedAt.Equal(*pr.MergedAt))
+}
+
+func testReassignReviewer(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	seedTeam(t, store, "backend", member("u1", true), member("u2", true), member("u3", true), member("u4", true), member("u5", false))
+	seedPR(t, store, "pr-1", "u1", "u2", "u3")
+
+	_, err := store.ReassignReviewer(ctx, "missing", "u2", func(*models.PullRequest, []models.User) (string, error) {
+		t.Fatal("chooser must not be called for a missing PR")
+		return "", nil
+	})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	var (
+		seenPR         *models.PullRequest
+		seenCandidates []models.User
+	)
+	newID, err := store.ReassignReviewer(ctx, "pr-1", "u2", func(pr *models.PullRequest, candidates []models.User) (string, error) {
+		seenPR, seenCandidates = pr, candidates
+		return "u4", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "u4", newID)
+	assert.Equal(t, []string{"u2", "u3"}, seenPR.AssignedReviewers)
+	assert.Equal(t, []string{"u1", "u3", "u4"}, userIDs(seenCandidates))
+
+	pr, err := store.GetPR(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u3", "u4"}, pr.AssignedReviewers)
+	assert.True(t, pr.UpdatedAt.After(*pr.CreatedAt) || pr.UpdatedAt.Equal(*pr.CreatedAt))
+
+	// An error from the chooser aborts the reassignment.
+	_, err = store.ReassignReviewer(ctx, "pr-1", "u3", func(*models.PullRequest, []models.User) (string, error) {
+		return "", service.ErrNoCandidate
+	})
+	assert.ErrorIs(t, err, service.ErrNoCandidate)
+
+	pr, err = store.GetPR(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u3", "u4"}, pr.AssignedReviewers)
+
+	// Replacing a reviewer that is not assigned fails and changes nothing.
+	_, err = store.ReassignReviewer(ctx, "pr-1", "u2", func(*models.PullRequest, []models.User) (string, error) {
+		return "u1", nil
+	})
+	assert.ErrorIs(t, err, repository.ErrNotAssigned)
+
+	pr, err = store.GetPR(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u3", "u4"}, pr.AssignedReviewers)
+}
+
+func testReviewDecisions(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	seedTeam(t, store, "backend", member("u1", true), member("u2", true), member("u3", true), member("u4", true))
+	seedPR(t, store, "pr-1", "u1", "u2", "u3")
+
+	assert.ErrorIs(t, store.RecordReviewDecision(ctx, "missing", "u2", models.DecisionApproved), sql.ErrNoRows)
+	assert.ErrorIs(t, store.RecordReviewDecision(ctx, "pr-1", "u1", models.DecisionApproved), sql.ErrNoRows)
+
+	require.NoError(t, store.RecordReviewDecision(ctx, "pr-1", "u2", models.DecisionChangesRequested))
+	pr, err := store.GetPR(ctx, "pr-1")
+	require.NoError(t, err)
+	require.Len(t, pr.Reviewers, 2)
+	require.NotNil(t, pr.Reviewers[0].Decision)
+	assert.Equal(t, models.DecisionChangesRequested, *pr.Reviewers[0].Decision)
+	require.NotNil(t, pr.Reviewers[0].DecidedAt)
+	firstDecidedAt := *pr.Reviewers[0].DecidedAt
+	assert.False(t, pr.UpdatedAt.Before(firstDecidedAt))
+	assert.Nil(t, pr.Reviewers[1].Decision)
+	assert.Nil(t, pr.Reviewers[1].DecidedAt)
+
+	// A reviewer may change their mind; the latest decision is reported.
+	require.NoError(t, store.RecordReviewDecision(ctx, "pr-1", "u2", models.DecisionApproved))
+
+	prs, err := store.ListPRs(ctx, models.PRListFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	require.NotNil(t, prs[0].Reviewers[0].Decision)
+	assert.Equal(t, models.DecisionApproved, *prs[0].Reviewers[0].Decision)
+	assert.False(t, prs[0].Reviewers[0].DecidedAt.Before(firstDecidedAt))
+
+	var exported []*models.PullRequest
+	require.NoError(t, store.StreamPRs(ctx, func(pr *models.PullRequest) error {
+		exported = append(exported, pr)
+		return nil
+	}))
+	require.Len(t, exported, 1)
+	require.NotNil(t, exported[0].Reviewers[0].Decision)
+	assert.Equal(t, models.DecisionApproved, *exported[0].Reviewers[0].Decision)
+	assert.Nil(t, exported[0].Reviewers[1].Decision)
+
+	// The decision goes with the assignment.
+	_, err = store.ReassignReviewer(ctx, "pr-1", "u2", func(*models.PullRequest, []models.User) (string, error) {
+		return "u4", nil
+	})
+	require.NoError(t, err)
+	pr, err = store.GetPR(ctx, "pr-1")
+	require.NoError(t, err)
+	for _, reviewer := range pr.Reviewers {
+		assert.Nil(t, reviewer.Decision, reviewer.UserID)
+	}
+
+	require.NoError(t, store.MergePR(ctx, "pr-1"))
+	assert.ErrorIs(t, store.RecordReviewDecision(ctx, "pr-1", "u3", models.DecisionApproved), sql.ErrNoRows)
+}
+
+func testListPRs(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	seedTeam(t, store, "backend", member("u1", true), member("u2", true))
+	seedTeam(t, store, "frontend", member("u3", true), member("u4", true))
+
+	for _, id := range []string{"pr-1", "pr-2", "pr-3"} {
+		seedPR(t, store, id, "u1", "u2")
+	}
+	seedPR(t, store, "pr-4", "u3", "u4")
+	require.NoError(t, store.MergePR(ctx, "pr-2"))
+
+	list := func(filter models.PRListFilter) []string {
+		t.Helper()
+		if filter.Limit == 0 {
+			filter.Limit = 10
+		}
+		prs, err := store.ListPRs(ctx, filter)
+		require.NoError(t, err)
+		ids := make([]string, 0, len(prs))
+		for _, pr := range prs {
+			ids = append(ids, pr.PullRequestID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"pr-4", "pr-3", "pr-2", "pr-1"}, list(models.PRListFilter{}))
+	assert.Equal(t, []string{"pr-1", "pr-2", "pr-3", "pr-4"}, list(models.PRListFilter{SortAsc: true}))
+	assert.Equal(t, []string{"pr-2"}, list(models.PRListFilter{Status: "MERGED"}))
+	assert.Equal(t, []string{"pr-4"}, list(models.PRListFilter{AuthorID: "u3"}))
+	assert.Equal(t, []string{"pr-3", "pr-2", "pr-1"}, list(models.PRListFilter{ReviewerID: "u2"}))
+	assert.Equal(t, []string{"pr-4"}, list(models.PRListFilter{TeamName: "frontend"}))
+	assert.Equal(t, []string{"pr-3"}, list(models.PRListFilter{Name: "pr PR-3"}))
+	assert.Equal(t, []string{"pr-4", "pr-3"}, list(models.PRListFilter{Limit: 2}))
+
+	prs, err := store.ListPRs(ctx, models.PRListFilter{Limit: 2})
+	require.NoError(t, err)
+	last := prs[len(prs)-1]
+	after := &models.PRCursor{CreatedAt: *last.CreatedAt, PullRequestID: last.PullRequestID}
+	assert.Equal(t, []string{"pr-2", "pr-1"}, list(models.PRListFilter{After: after}))
+
+	merged, err := store.GetPR(ctx, "pr-2")
+	require.NoError(t, err)
+	from := merged.MergedAt.Add(-time.Second)
+	assert.Equal(t, []string{"pr-2"}, list(models.PRListFilter{MergedFrom: &from}))
+
+	future := time.Now().Add(time.Hour)
+	assert.Empty(t, list(models.PRListFilter{CreatedFrom: &future}))
+	assert.Len(t, list(models.PRListFilter{CreatedTo: &future}), 4)
+
+	prs, err = store.ListPRs(ctx, models.PRListFilter{AuthorID: "u3", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, []string{"u4"}, prs[0].AssignedReviewers)
+}
+
+func testUserPRs(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	seedTeam(t, store, "backend", member("u1", true), member("u2", true), member("u3", true))
+	seedPR(t, store, "pr-1", "u1", "u2")
+	seedPR(t, store, "pr-2", "u1", "u2", "u3")
+	seedPR(t, store, "pr-3", "u3", "u1")
+	require.NoError(t, store.MergePR(ctx, "pr-1"))
+
+	all, err := store.GetUserReviewPRs(ctx, "u2")
+	require.NoError(t, err)
+	assert.Equal(t, []models.PullRequestShort{
+		{PullRequestID: "pr-2", PullRequestName: "PR pr-2", AuthorID: "u1", Status: "OPEN"},
+		{PullRequestID: "pr-1", PullRequestName: "PR pr-1", AuthorID: "u1", Status: "MERGED"},
+	}, all)
+
+	open, err := store.GetUserOpenReviewPRs(ctx, "u2")
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, "pr-2", open[0].PullRequestID)
+
+	none, err := store.GetUserReviewPRs(ctx, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	require.NoError(t, store.MergePR(ctx, "pr-3"))
+
+	merged, err := store.GetUserRecentlyMergedPRs(ctx, "u1", time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, merged, 2)
+	assert.Equal(t, "pr-3", merged[0].PullRequestID)
+	assert.Equal(t, "pr-1", merged[1].PullRequestID)
+
+	merged, err = store.GetUserRecentlyMergedPRs(ctx, "u1", time.Now().Add(-time.Hour), 1)
+	require.NoError(t, err)
+	assert.Len(t, merged, 1)
+
+	merged, err = store.GetUserRecentlyMergedPRs(ctx, "u1", time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Empty(t, merged)
+
+	counts, err := store.CountOpenPRsByTeam(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"backend": 1}, counts)
+}
+
+func testStreamPRs(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	seedTeam(t, store, "backend", member("u1", true), member("u2", true))
+	seedPR(t, store, "pr-1", "u1", "u2")
+	seedPR(t, store, "pr-2", "u1")
+
+	var streamed []models.PullRequest
+	require.NoError(t, store.StreamPRs(ctx, func(pr *models.PullRequest) error {
+		streamed = append(streamed, *pr)
+		return nil
+	}))
+
+	require.Len(t, streamed, 2)
+	assert.Equal(t, "pr-1", streamed[0].PullRequestID)
+	assert.Equal(t, []string{"u2"}, streamed[0].AssignedReviewers)
+	require.Len(t, streamed[0].Reviewers, 1)
+	assert.Equal(t, "user u2", streamed[0].Reviewers[0].Username)
+	assert.Equal(t, "pr-2", streamed[1].PullRequestID)
+	assert.Empty(t, streamed[1].Reviewers)
+
+	stop := assert.AnError
+	calls := 0
+	err := store.StreamPRs(ctx, func(*models.PullRequest) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
+
+func testStatistics(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	seedTeam(t, store, "backend", member("u1", true), member("u2", true), member("u3", true))
+	seedTeam(t, store, "frontend", member("u4", true), member("u5", false))
+	seedPR(t, store, "pr-1", "u1", "u2", "u3")
+	seedPR(t, store, "pr-2", "u1", "u2")
+	seedPR(t, store, "pr-3", "u4")
+	require.NoError(t, store.MergePR(ctx, "pr-1"))
+
+	userStats, err := store.GetUserReviewStats(ctx, models.StatisticsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []models.UserReviewStats{
+		{UserID: "u2", Username: "user u2", ReviewCount: 2},
+		{UserID: "u3", Username: "user u3", ReviewCount: 1},
+		{UserID: "u1", Username: "user u1", ReviewCount: 0},
+		{UserID: "u4", Username: "user u4", ReviewCount: 0},
+		{UserID: "u5", Username: "user u5", ReviewCount: 0},
+	}, userStats)
+
+	userStats, err = store.GetUserReviewStats(ctx, models.StatisticsFilter{TeamName: "backend", Status: "MERGED"})
+	require.NoError(t, err)
+	assert.Equal(t, []models.UserReviewStats{
+		{UserID: "u2", Username: "user u2", ReviewCount: 1},
+		{UserID: "u3", Username: "user u3", ReviewCount: 1},
+		{UserID: "u1", Username: "user u1", ReviewCount: 0},
+	}, userStats)
+
+	prStats, err := store.GetPRReviewStats(ctx, models.StatisticsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []models.PRReviewStats{
+		{PullRequestID: "pr-3", PullRequestName: "PR pr-3", ReviewerCount: 0},
+		{PullRequestID: "pr-2", PullRequestName: "PR pr-2", ReviewerCount: 1},
+		{PullRequestID: "pr-1", PullRequestName: "PR pr-1", ReviewerCount: 2},
+	}, prStats)
+
+	prStats, err = store.GetPRReviewStats(ctx, models.StatisticsFilter{TeamName: "frontend"})
+	require.NoError(t, err)
+	require.Len(t, prStats, 1)
+	assert.Equal(t, "pr-3", prStats[0].PullRequestID)
+
+	future := time.Now().Add(time.Hour)
+	prStats, err = store.GetPRReviewStats(ctx, models.StatisticsFilter{From: &future})
+	require.NoError(t, err)
+	assert.Empty(t, prStats)
+
+	_, err = store.GetReviewTimeline(ctx, models.StatisticsFilter{Bucket: "month"})
+	assert.Error(t, err)
+
+	for _, bucket := range []string{"day", "week"} {
+		timeline, err := store.GetReviewTimeline(ctx, models.StatisticsFilter{Bucket: bucket})
+		require.NoError(t, err)
+
+		var created, merged, assignments int
+		for _, b := range timeline {
+			created += b.PRsCreated
+			merged += b.PRsMerged
+			assignments += b.Assignments
+			assert.False(t, b.PeriodStart.After(time.Now()))
+		}
+		assert.Equal(t, 3, created, bucket)
+		assert.Equal(t, 1, merged, bucket)
+		assert.Equal(t, 3, assignments, bucket)
+	}
+
+	from := time.Now().Add(-time.Hour)
+	to := time.Now().Add(time.Hour)
+	members, err := store.GetActiveMemberAssignments(ctx, from, to, "")
+	require.NoError(t, err)
+	require.Len(t, members, 4)
+	assert.Equal(t, "u1", members[0].UserID)
+	assert.Equal(t, "backend", members[0].TeamName)
+	assert.Equal(t, 2, members[1].Assignments)
+	assert.Equal(t, "u4", members[3].UserID)
+	assert.False(t, members[0].CreatedAt.IsZero())
+
+	members, err = store.GetActiveMemberAssignments(ctx, to, to.Add(time.Hour), "backend")
+	require.NoError(t, err)
+	require.Len(t, members, 3)
+	for _, m := range members {
+		assert.Zero(t, m.Assignments)
+	}
+}
+
+func testLatency(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	seedTeam(t, store, "backend", member("u1", true), member("u2", true), member("u3", true))
+	seedPR(t, store, "pr-1", "u1", "u2", "u3")
+	seedPR(t, store, "pr-2", "u2", "u3")
+	seedPR(t, store, "pr-3", "u1", "u2")
+	require.NoError(t, store.RecordReviewDecision(ctx, "pr-1", "u2", models.DecisionApproved))
+	require.NoError(t, store.RecordReviewDecision(ctx, "pr-3", "u2", models.DecisionChangesRequested))
+	require.NoError(t, store.RecordReviewDecision(ctx, "pr-3", "u2", models.DecisionApproved))
+	require.NoError(t, store.MergePR(ctx, "pr-1"))
+	require.NoError(t, store.MergePR(ctx, "pr-2"))
+
+	_, err := store.GetLatencyStats(ctx, "unknown", models.StatisticsFilter{})
+	assert.Error(t, err)
+
+	counts := func(metric string, filter models.StatisticsFilter) map[string]int {
+		t.Helper()
+		stats, err := store.GetLatencyStats(ctx, metric, filter)
+		require.NoError(t, err)
+		result := make(map[string]int, len(stats))
+		for i, stat := range stats {
+			if i > 0 {
+				assert.Less(t, stats[i-1].Key, stat.Key)
+			}
+			assert.GreaterOrEqual(t, stat.P50Seconds, 0.0)
+			assert.LessOrEqual(t, stat.P50Seconds, stat.P90Seconds)
+			assert.LessOrEqual(t, stat.P90Seconds, stat.P99Seconds)
+			result[stat.Key] = stat.Count
+		}
+		return result
+	}
+
+	assert.Equal(t, map[string]int{"backend": 2}, counts(repository.LatencyTimeToMergeByTeam, models.StatisticsFilter{}))
+	assert.Equal(t, map[string]int{"u1": 1, "u2": 1}, counts(repository.LatencyTimeToMergeByAuthor, models.StatisticsFilter{}))
+	assert.Equal(t, map[string]int{"u2": 1, "u3": 2}, counts(repository.LatencyTimeToMergeByReviewer, models.StatisticsFilter{}))
+	assert.Equal(t, map[string]int{"u2": 1, "u3": 2}, counts(repository.LatencyAssignmentToMergeByReviewer, models.StatisticsFilter{}))
+
+	weeks := counts(repository.LatencyTimeToMergeByWeek, models.StatisticsFilter{})
+	total := 0
+	for week, count := range weeks {
+		_, err := time.Parse("2006-01-02", week)
+		assert.NoError(t, err)
+		total += count
+	}
+	assert.Equal(t, 2, total)
+
+	// Decisions count once per assignment, whether or not the PR is merged.
+	assert.Equal(t, map[string]int{"u2": 2}, counts(repository.LatencyAssignmentToFirstDecisionByReviewer, models.StatisticsFilter{}))
+	total = 0
+	for _, count := range counts(repository.LatencyAssignmentToFirstDecisionByWeek, models.StatisticsFilter{}) {
+		total += count
+	}
+	assert.Equal(t, 2, total)
+
+	future := time.Now().Add(time.Hour)
+	assert.Empty(t, counts(repository.LatencyTimeToMergeByTeam, models.StatisticsFilter{From: &future}))
+	assert.Empty(t, counts(repository.LatencyTimeToMergeByTeam, models.StatisticsFilter{TeamName: "frontend"}))
+	assert.Empty(t, counts(repository.LatencyAssignmentToFirstDecisionByReviewer, models.StatisticsFilter{From: &future}))
+}
+
+func testGraph(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	seedTeam(t, store, "backend", member("u1", true), member("u2", true), member("u3", true))
+	seedPR(t, store, "pr-1", "u1", "u2", "u3")
+	seedPR(t, store, "pr-2", "u1", "u2")
+	seedPR(t, store, "pr-3", "u2", "u1")
+	seedPR(t, store, "pr-4", "u3")
+
+	edges, err := store.GetReviewEdges(ctx, models.StatisticsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []models.GraphEdge{
+		{AuthorID: "u1", ReviewerID: "u2", Weight: 2},
+		{AuthorID: "u1", ReviewerID: "u3", Weight: 1},
+		{AuthorID: "u2", ReviewerID: "u1", Weight: 1},
+	}, edges)
+
+	edges, err = store.GetReviewEdges(ctx, models.StatisticsFilter{TeamName: "frontend"})
+	require.NoError(t, err)
+	assert.Empty(t, edges)
+
+	// u3's PR has no reviewers, so u3 is an author without edges.
+	authors, err := store.GetPRAuthors(ctx, models.StatisticsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2", "u3"}, authors)
+
+	authors, err = store.GetPRAuthors(ctx, models.StatisticsFilter{TeamName: "frontend"})
+	require.NoError(t, err)
+	assert.Empty(t, authors)
+
+	nodes, err := store.GetGraphNodes(ctx, []string{"u3", "u1", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, []models.GraphNode{
+		{UserID: "u1", Username: "user u1", TeamName: "backend"},
+		{UserID: "u3", Username: "user u3", TeamName: "backend"},
+	}, nodes)
+
+	nodes, err = store.GetGraphNodes(ctx, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, nodes)
+	assert.Empty(t, nodes)
+}
+
+func testAPIKeys(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	createdAt := time.Now().Truncate(time.Microsecond)
+	key := &models.APIKey{KeyID: "k1", Name: "ci", Role: models.RoleIntegration, CreatedAt: createdAt}
+	require.NoError(t, store.CreateAPIKey(ctx, key, "hash-1"))
+	require.NoError(t, store.CreateAPIKey(ctx, &models.APIKey{KeyID: "k2", Name: "dash", Role: models.RoleReadOnly, CreatedAt: createdAt.Add(time.Second)}, "hash-2"))
+
+	assert.ErrorIs(t, store.CreateAPIKey(ctx, &models.APIKey{KeyID: "k3", Role: models.RoleAdmin, CreatedAt: createdAt}, "hash-1"), repository.ErrDuplicate)
+
+	found, err := store.GetAPIKeyByHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, "k1", found.KeyID)
+	assert.Equal(t, models.RoleIntegration, found.Role)
+	assert.True(t, createdAt.Equal(found.CreatedAt))
+	assert.Nil(t, found.RevokedAt)
+
+	_, err = store.GetAPIKeyByHash(ctx, "unknown")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, store.RevokeAPIKey(ctx, "k1"))
+	assert.ErrorIs(t, store.RevokeAPIKey(ctx, "k1"), sql.ErrNoRows)
+	assert.ErrorIs(t, store.RevokeAPIKey(ctx, "missing"), sql.ErrNoRows)
+
+	_, err = store.GetAPIKeyByHash(ctx, "hash-1")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	keys, err := store.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "k1", keys[0].KeyID)
+	assert.NotNil(t, keys[0].RevokedAt)
+	assert.Equal(t, "k2", keys[1].KeyID)
+	assert.Nil(t, keys[1].RevokedAt)
+}
+
+func testIdempotency(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	reserved, err := store.ReserveIdempotencyKey(ctx, "key", "hash", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	reserved, err = store.ReserveIdempotencyKey(ctx, "key", "other", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+
+	record, err := store.GetIdempotencyRecord(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "hash", record.RequestHash)
+	assert.Nil(t, record.StatusCode)
+
+	headers := []byte(`{"Content-Type":["application/json"],"Location":["/v2/teams/backend"]}`)
+	require.NoError(t, store.CompleteIdempotencyKey(ctx, "key", 201, headers, []byte(`{"ok":true}`), time.Hour))
+	assert.ErrorIs(t, store.CompleteIdempotencyKey(ctx, "missing", 200, nil, nil, time.Hour), sql.ErrNoRows)
+
+	record, err = store.GetIdempotencyRecord(ctx, "key")
+	require.NoError(t, err)
+	require.NotNil(t, record.StatusCode)
+	assert.Equal(t, 201, *record.StatusCode)
+	assert.JSONEq(t, string(headers), string(record.ResponseHeaders))
+	assert.Equal(t, []byte(`{"ok":true}`), record.ResponseBody)
+
+	require.NoError(t, store.DeleteIdempotencyKey(ctx, "key"))
+	_, err = store.GetIdempotencyRecord(ctx, "key")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Expired keys can be claimed again and are purged.
+	reserved, err = store.ReserveIdempotencyKey(ctx, "expired", "hash", -time.Second)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	reserved, err = store.ReserveIdempotencyKey(ctx, "expired", "other", -time.Second)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	_, err = store.ReserveIdempotencyKey(ctx, "live", "hash", time.Hour)
+	require.NoError(t, err)
+
+	purged, err := store.PurgeExpiredIdempotencyKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	_, err = store.GetIdempotencyRecord(ctx, "live")
+	assert.NoError(t, err)
+
+	// A pending key holds only its lease; completing it keeps the response
+	// for the full TTL.
+	reserved, err = store.ReserveIdempotencyKey(ctx, "crashed", "hash", -time.Second)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	reserved, err = store.ReserveIdempotencyKey(ctx, "crashed", "hash", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, reserved, "an abandoned reservation is reclaimed once its lease expires")
+
+	reserved, err = store.ReserveIdempotencyKey(ctx, "answered", "hash", -time.Second)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	require.NoError(t, store.CompleteIdempotencyKey(ctx, "answered", 200, nil, nil, time.Hour))
+	reserved, err = store.ReserveIdempotencyKey(ctx, "answered", "hash", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, reserved, "a completed response is kept for the TTL")
+}
+
+func testCancelledContext(t *testing.T, store service.Store) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := store.GetUser(ctx, "u1")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.Error(t, store.CreateTeam(ctx, "backend", nil))
+
+	exists, err := store.TeamExists(context.Background(), "backend")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func testHealth(t *testing.T, store service.Store) {
+	ctx := context.Background()
+
+	require.NoError(t, store.Ping(ctx))
+
+	version, err := store.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, version.Expected)
+	assert.Equal(t, version.Expected, version.Current)
+}
@@ -37,7 +37,8 @@ func SetupTestDB(ctx context.Context) (*sqlx.DB, func(), error) {
 		return nil, nil, fmt.Errorf("failed to start postgres container: %w", err)
 	}
 
-	connStr, err := postgresContainer.ConnectionString(ctx, "sslmode=disable")
+	// The service pins the session to UTC; see app.dataSourceName.
+	connStr, err := postgresContainer.ConnectionString(ctx, "sslmode=disable", "timezone=UTC")
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get connection string: %w", err)
 	}
@@ -51,7 +52,7 @@ func SetupTestDB(ctx context.Context) (*sqlx.DB, func(), error) {
 		return nil, nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	if err := applyMigrations(db); err != nil {
+	if err := applyMigrations(db, "db/pr.sql"); err != nil {
 		return nil, nil, fmt.Errorf("failed to apply migrations: %w", err)
 	}
 
@@ -65,8 +66,7 @@ func SetupTestDB(ctx context.Context) (*sqlx.DB, func(), error) {
 	return db, cleanup, nil
 }
 
-func applyMigrations(db *sqlx.DB) error {
-	migrationPath := "db/pr.sql"
+func applyMigrations(db *sqlx.DB, migrationPath string) error {
 
 	if _, err := os.Stat(migrationPath); os.IsNotExist(err) {
 		wd, _ := os.Getwd()